@@ -0,0 +1,209 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"os"
+	"os/signal"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/dalibo/ldap2pg/v6/internal/config"
+	"github.com/dalibo/ldap2pg/v6/internal/errorlist"
+	"github.com/dalibo/ldap2pg/v6/internal/inspect"
+	"github.com/dalibo/ldap2pg/v6/internal/ldap"
+	"github.com/dalibo/ldap2pg/v6/internal/perf"
+	"github.com/dalibo/ldap2pg/v6/internal/postgres"
+	"github.com/dalibo/ldap2pg/v6/internal/privileges"
+)
+
+// daemonStatus is served as JSON on /status.
+type daemonStatus struct {
+	mu sync.Mutex
+
+	Running     bool       `json:"running"`
+	Runs        int        `json:"runs"`
+	LastRun     *runStatus `json:"last_run,omitempty"`
+	LastSuccess time.Time  `json:"last_success,omitzero"`
+	NextRun     time.Time  `json:"next_run,omitzero"`
+
+	metrics runMetrics
+}
+
+type runStatus struct {
+	Start   time.Time      `json:"start"`
+	End     time.Time      `json:"end"`
+	Success bool           `json:"success"`
+	Error   string         `json:"error,omitempty"`
+	Roles   int            `json:"roles"`
+	Queries int            `json:"queries"`
+	Changes map[string]int `json:"changes"`
+}
+
+// daemon synchronizes Postgres instance every interval until SIGTERM or SIGINT.
+//
+// SIGHUP reloads YAML configuration and triggers a synchronization. LDAP and
+// Postgres connections are kept open between runs.
+func daemon(ctx context.Context, controller Controller, conf config.Config) error {
+	slog.Info("Running as daemon.", "interval", controller.Interval)
+	ldap.KeepAlive = true
+	defer ldap.Disconnect()
+
+	status := &daemonStatus{}
+	if controller.Listen != "" {
+		server := &http.Server{Addr: controller.Listen, Handler: status.handler()}
+		go func() {
+			slog.Info("Serving HTTP.", "addr", controller.Listen)
+			err := server.ListenAndServe()
+			if err != nil && !errors.Is(err, http.ErrServerClosed) {
+				slog.Error("HTTP server error.", "err", err)
+			}
+		}()
+		defer server.Shutdown(ctx) //nolint:errcheck
+	}
+
+	sigs := make(chan os.Signal, 1)
+	signal.Notify(sigs, syscall.SIGHUP, syscall.SIGTERM, syscall.SIGINT)
+	defer signal.Stop(sigs)
+	reload := make(chan struct{}, 1)
+	stop := make(chan struct{})
+	go func() {
+		for sig := range sigs {
+			if sig == syscall.SIGHUP {
+				slog.Info("Received SIGHUP. Reloading configuration.")
+				select {
+				case reload <- struct{}{}:
+				default:
+				}
+				continue
+			}
+			slog.Info("Received signal. Stopping after current query.", "signal", sig)
+			// Stop Apply before next query. Don't cancel context to let
+			// current query finish.
+			postgres.Interrupt()
+			close(stop)
+			return
+		}
+	}()
+
+	timer := time.NewTimer(0)
+	defer timer.Stop()
+	for {
+		select {
+		case <-stop:
+			slog.Info("Stopping daemon.")
+			return nil
+		case <-reload:
+			var err error
+			conf, err = reloadConfig(controller, conf)
+			if err != nil {
+				slog.Error("Failed to reload configuration. Keeping previous configuration.", "err", err)
+				continue
+			}
+			timer.Reset(0)
+		case <-timer.C:
+			status.begin()
+			ldap.Watch = perf.StopWatch{}
+			inspect.Watch = perf.StopWatch{}
+			postgres.Watch = perf.StopWatch{}
+			err := run(ctx, controller, conf, time.Now())
+			if err != nil {
+				for _, werr := range errorlist.Unwrap(err) {
+					slog.Error(werr.Error())
+				}
+				// Reset connections, they may be broken.
+				postgres.CloseConn(ctx)
+				ldap.Disconnect()
+			}
+			snapshot := metrics.collect()
+			metrics.LastSuccess = snapshot.LastSuccess
+			next := time.Now().Add(controller.Interval)
+			status.end(snapshot, err, next)
+			slog.Info("Next synchronization.", "at", next.Format(time.DateTime))
+			timer.Reset(controller.Interval)
+		}
+	}
+}
+
+// reloadConfig loads configuration again, resetting privileges registries.
+//
+// Restores previous configuration on error.
+func reloadConfig(controller Controller, previous config.Config) (config.Config, error) {
+	privileges.Reset()
+	c, _, err := loadConfig(controller)
+	if err == nil && c.Rules.HasLDAPSearches() {
+		ldap.Disconnect()
+		err = ldap.Initialize(c.Ldap)
+	}
+	if err != nil {
+		privileges.Reset()
+		if !controller.SkipPrivileges {
+			_ = previous.RegisterPrivileges()
+		}
+		return previous, err
+	}
+	return c, nil
+}
+
+func (s *daemonStatus) begin() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.Running = true
+	s.NextRun = time.Time{}
+}
+
+func (s *daemonStatus) end(m runMetrics, err error, next time.Time) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.Running = false
+	s.Runs++
+	s.NextRun = next
+	s.metrics = m
+	s.LastSuccess = m.LastSuccess
+	s.LastRun = &runStatus{
+		Start:   m.Start,
+		End:     m.End,
+		Success: err == nil,
+		Roles:   m.Roles,
+		Queries: m.Queries,
+		Changes: m.Changes,
+	}
+	if err != nil {
+		s.LastRun.Error = err.Error()
+	}
+}
+
+func (s *daemonStatus) handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /healthz", func(w http.ResponseWriter, _ *http.Request) {
+		s.mu.Lock()
+		failed := s.LastRun != nil && !s.LastRun.Success
+		s.mu.Unlock()
+		if failed {
+			http.Error(w, "last synchronization failed", http.StatusServiceUnavailable)
+			return
+		}
+		_, _ = w.Write([]byte("ok\n"))
+	})
+	mux.HandleFunc("GET /status", func(w http.ResponseWriter, _ *http.Request) {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(s)
+	})
+	mux.HandleFunc("GET /metrics", func(w http.ResponseWriter, _ *http.Request) {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		if s.LastRun == nil {
+			http.Error(w, "no synchronization yet", http.StatusServiceUnavailable)
+			return
+		}
+		w.Header().Set("Content-Type", "text/plain; version=0.0.4")
+		_ = s.metrics.WriteProm(w)
+	})
+	return mux
+}
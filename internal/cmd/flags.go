@@ -1,10 +1,13 @@
 package cmd
 
 import (
+	"errors"
 	"fmt"
 	"log/slog"
 	"math"
 	"os"
+	"path/filepath"
+	"slices"
 	"strings"
 	"time"
 
@@ -23,17 +26,25 @@ import (
 	"github.com/spf13/pflag"
 )
 
-var k = koanf.New(".")
+var (
+	k        = koanf.New(".")
+	commands = []string{"plan", "apply"}
+)
 
 func init() {
 	pflag.Usage = func() {
-		fmt.Fprintf(os.Stderr, "usage: %s [OPTIONS] [dbname]\n\n", os.Args[0])
+		fmt.Fprintf(os.Stderr, "usage: %s [OPTIONS] [dbname]\n", os.Args[0])
+		fmt.Fprintf(os.Stderr, "       %s plan [OPTIONS] [dbname]\n", os.Args[0])
+		fmt.Fprintf(os.Stderr, "       %s apply [OPTIONS] PLANFILE [dbname]\n\n", os.Args[0])
 		pflag.PrintDefaults()
 		_, _ = os.Stderr.Write([]byte(dedent.Dedent(`
 
 		Optional argument dbname is alternatively the database name or a conninfo string or an URI.
 		See man psql(1) for more information.
 
+		plan command computes changes in dry mode and saves them to a plan file.
+		apply command executes a plan file if Postgres is unchanged since planning.
+
 		By default, ldap2pg runs in dry mode.
 		ldap2pg requires a configuration file to describe LDAP searches and mappings.
 		See https://ldap2pg.readthedocs.io/en/latest/ for further details.
@@ -56,14 +67,22 @@ func loadEnvAndFlags() {
 	pflag.Bool("check", false, "Check mode: exits with 1 if Postgres instance is unsynchronized.")
 	pflag.Bool("color", defaultColor(), "Force color output.")
 	pflag.StringP("config", "c", k.String("config"), "Path to YAML configuration file. Use - for stdin.")
+	pflag.Bool("daemon", k.Bool("daemon"), "Synchronize every interval until SIGTERM.")
 	pflag.StringP("directory", "C", "", "Path to directory containing configuration files.")
+	pflag.Bool("force", false, "Apply changes exceeding max_dropped_roles or max_revoked_grants.")
 	pflag.BoolP("real", "R", k.Bool("real"), "Real mode. Apply changes to Postgres instance.")
 	pflag.BoolP("skip-privileges", "P", k.Bool("skipprivileges"), "Turn off privilege synchronisation.")
 	pflag.BoolP("help", "?", false, "Show this help message and exit.")
 	pflag.BoolP("version", "V", false, "Show version and exit.")
 	pflag.CountP("quiet", "q", "Decrease log verbosity.")
 	pflag.CountP("verbose", "v", "Increase log verbosity.")
+	pflag.Duration("interval", defaultDuration(k.Duration("interval"), 5*time.Minute), "Delay between synchronizations in daemon mode.")
+	pflag.String("listen", defaultString(k.String("listen"), "localhost:8642"), "Daemon HTTP address for /healthz, /status and /metrics. Empty disables HTTP.")
 	pflag.StringP("ldappassword-file", "y", "", "Path to LDAP password file.")
+	pflag.StringP("output", "o", k.String("output"), "Path to plan output file. Defaults to standard output.")
+	pflag.String("plan-format", k.String("planformat"), "Write queries in this format. Accepts json or sql.")
+	pflag.String("metrics-file", k.String("metricsfile"), "Write Prometheus metrics to this file.")
+	pflag.String("transaction", k.String("transaction"), "Wrap changes in transactions. Accepts database or all.")
 	pflag.Parse()
 
 	// posflag.Provider does not return error.
@@ -95,6 +114,46 @@ type Controller struct {
 	LogLevel       slog.Level
 	Directory      string
 	Dsn            string
+	PlanFormat     string
+	Output         string
+	Command        string
+	PlanFile       string
+	Transaction    string
+	Force          bool
+	MetricsFile    string
+	Daemon         bool
+	Interval       time.Duration
+	Listen         string
+}
+
+// checkThreshold refuses to apply count changes over limit in real mode.
+//
+// A negative limit means no limit. --force bypasses the threshold.
+func (controller Controller) checkThreshold(param string, count, limit int) error {
+	if limit < 0 || count <= limit {
+		return nil
+	}
+	attrs := []any{"param", param, "count", count, "max", limit}
+	// Let apply check threshold again.
+	postgres.CurrentPlan.Exceed(param, count, limit)
+	if !controller.Real {
+		slog.Warn("Too many changes. Real mode would abort.", attrs...)
+		return nil
+	}
+	if controller.Force {
+		slog.Warn("Too many changes. Forcing synchronization.", attrs...)
+		return nil
+	}
+	return thresholdError{param: param, count: count, limit: limit}
+}
+
+type thresholdError struct {
+	param        string
+	count, limit int
+}
+
+func (err thresholdError) Error() string {
+	return fmt.Sprintf("%d changes exceed %s (%d), use --force to apply anyway", err.count, err.param, err.limit)
 }
 
 // Finalize logs the end of ldap2pg execution and determine exit code.
@@ -104,11 +163,17 @@ func (controller Controller) Finalize(errs *errorlist.List, start time.Time, rol
 		"roles", roles,
 		"queries", queries, // Don't use Watch.Count for dry run case.
 	}
-	if !controller.SkipPrivileges {
+	// grants is negative when applying a plan.
+	if !controller.SkipPrivileges && grants >= 0 {
 		logAttrs = append(logAttrs,
 			"grants", grants,
 		)
 	}
+	metrics.Roles = roles
+	metrics.Grants = grants
+	metrics.Queries = queries
+	metrics.Success = errs.Len() == 0
+
 	memUsed := perf.ReadMemoryWatermark()
 	elapsed := time.Since(start)
 	logAttrs = append(logAttrs,
@@ -127,7 +192,7 @@ func (controller Controller) Finalize(errs *errorlist.List, start time.Time, rol
 			finalMessage = "Comparison complete."
 		}
 		slog.Info(finalMessage, logAttrs...)
-		if !controller.Real {
+		if !controller.Real && controller.Command != "plan" {
 			slog.Info("Use --real option to apply changes.")
 		}
 	} else {
@@ -166,6 +231,8 @@ func unmarshalController() (controller Controller, err error) {
 
 	controller.Directory = homedir.Expand(controller.Directory)
 	controller.Config = homedir.Expand(controller.Config)
+	controller.Output = homedir.Expand(controller.Output)
+	controller.MetricsFile = homedir.Expand(controller.MetricsFile)
 
 	verbosity := k.String("verbosity")
 	var level slog.LevelVar
@@ -187,12 +254,76 @@ func unmarshalController() (controller Controller, err error) {
 		}
 	}
 	args := pflag.Args()
+	if len(os.Args) > 1 && len(args) > 0 && os.Args[1] == args[0] && slices.Contains(commands, args[0]) {
+		controller.Command = args[0]
+		args = args[1:]
+	}
+
+	switch controller.Command {
+	case "plan":
+		if controller.Real {
+			return controller, errors.New("plan command runs in dry mode")
+		}
+		// Plan file is always JSON, whatever the file extension.
+		if controller.PlanFormat == "" {
+			controller.PlanFormat = "json"
+		}
+		if controller.PlanFormat != "json" {
+			return controller, errors.New("plan command requires json format")
+		}
+	case "apply":
+		if len(args) == 0 {
+			return controller, errors.New("missing plan file")
+		}
+		controller.PlanFile = homedir.Expand(args[0])
+		args = args[1:]
+		controller.Real = true
+	}
+
+	if controller.PlanFormat == "" && controller.Output != "" {
+		// Infer format from output file extension.
+		controller.PlanFormat = strings.TrimPrefix(filepath.Ext(controller.Output), ".")
+	}
+	switch controller.PlanFormat {
+	case "", "json", "sql":
+	default:
+		return controller, fmt.Errorf("unknown plan format: %s", controller.PlanFormat)
+	}
+	if controller.Daemon {
+		if controller.Command != "" || controller.Check || controller.PlanFormat != "" {
+			return controller, errors.New("daemon mode is incompatible with commands, check mode and plan output")
+		}
+		if controller.Interval <= 0 {
+			return controller, errors.New("interval must be positive")
+		}
+	}
+
+	switch controller.Transaction {
+	case "", "database", "all":
+	default:
+		return controller, fmt.Errorf("unknown transaction mode: %s", controller.Transaction)
+	}
+
 	if len(args) > 0 {
 		controller.Dsn = args[0]
 	}
 	return controller, err
 }
 
+func defaultDuration(value, fallback time.Duration) time.Duration {
+	if value == 0 {
+		return fallback
+	}
+	return value
+}
+
+func defaultString(value, fallback string) string {
+	if value == "" {
+		return fallback
+	}
+	return value
+}
+
 func notify(title, message string) {
 	if !isatty.IsTerminal(os.Stdout.Fd()) {
 		return
@@ -19,6 +19,7 @@ import (
 	"github.com/dalibo/ldap2pg/v6/internal/errorlist"
 	"github.com/dalibo/ldap2pg/v6/internal/inspect"
 	"github.com/dalibo/ldap2pg/v6/internal/ldap"
+	"github.com/dalibo/ldap2pg/v6/internal/lists"
 	"github.com/dalibo/ldap2pg/v6/internal/postgres"
 	"github.com/dalibo/ldap2pg/v6/internal/privileges"
 	"github.com/dalibo/ldap2pg/v6/internal/role"
@@ -80,46 +81,175 @@ func ldap2pg() (err error) {
 		return
 	}
 
+	if controller.Daemon {
+		return daemon(ctx, controller, conf)
+	}
+	return run(ctx, controller, conf, start)
+}
+
+// run synchronizes Postgres instance once.
+func run(ctx context.Context, controller Controller, conf config.Config, start time.Time) (err error) {
+	metrics = runMetrics{
+		Start:       start,
+		Real:        controller.Real,
+		Changes:     map[string]int{},
+		LastSuccess: metrics.LastSuccess,
+	}
+	if controller.MetricsFile != "" {
+		defer func() {
+			err := writeMetricsFile(controller.MetricsFile)
+			if err != nil {
+				slog.Error("Failed to write metrics.", "path", controller.MetricsFile, "err", err)
+			}
+		}()
+	}
+
+	// Don't reuse audit of previous run in daemon mode.
+	postgres.CurrentAudit = nil
+	if controller.Real && conf.Audit.Table != "" {
+		audit := &conf.Audit
+		err = audit.Open(ctx)
+		if err != nil {
+			audit.Close(ctx)
+			return fmt.Errorf("audit: %w", err)
+		}
+		postgres.CurrentAudit = audit
+		defer func() {
+			postgres.CurrentAudit = nil
+			audit.Close(ctx)
+		}()
+	}
+
+	if controller.Command == "apply" {
+		return applyPlan(ctx, controller, conf, start)
+	}
+
+	if controller.PlanFormat != "" {
+		postgres.CurrentPlan = postgres.NewPlan()
+	}
+
 	pc := conf.Postgres.Build()
 	// Inspect session, running user, user options, blacklist, etc.
+	defer postgres.Unlock(ctx)
 	instance, err := inspect.Stage0(ctx, pc)
 	if err != nil {
-		return
+		return lockError(err)
 	}
-	wantedRoles, wantedGrants, err := conf.Rules.Run(instance.RolesBlacklist)
-	if err != nil {
+	syncErrors := errorlist.New("synchronization errors")
+	wantedRoles, wantedGrants, err := conf.Rules.Run(instance.RolesBlacklist, conf.Postgres.RoleConflicts)
+	// Unexpected entry count skips drops and revokes but does not stop
+	// synchronization. Roles and grants of the failing search are unknown,
+	// thus skip removals for all rules.
+	skipRemovals := err != nil && lists.And(errorlist.Unwrap(err), func(err error) bool {
+		return errors.Is(err, ldap.ErrEntryCount)
+	})
+	if err != nil && !skipRemovals {
 		return
 	}
+	syncErrors.Append(err)
+
 	// Inspect users and databases (for drop owned by loop).
 	err = instance.InspectStage1(ctx, pc)
 	if err != nil {
 		return
 	}
-
-	syncErrors := errorlist.New("synchronization errors")
+	err = postgres.CheckTransactionMode(instance.DefaultDatabase)
+	if err != nil {
+		return
+	}
+	var fingerprint inspect.Fingerprint
+	fingerprint.AddRoles(instance.AllRoles)
 
 	// Synchronize roles.
-	queries := role.Diff(instance.AllRoles, instance.ManagedRoles, wantedRoles, instance.FallbackOwner)
+	managed := instance.ManagedRoles
+	spurious := role.Spurious(instance.AllRoles, managed, wantedRoles)
+	if skipRemovals {
+		slog.Warn("Unexpected entry count. Skipping drops and revokes for all rules.")
+		// Hide spurious roles from diff.
+		managed = maps.Clone(managed)
+		for _, name := range spurious {
+			slog.Warn("Not dropping role.", "role", name)
+			delete(managed, name)
+		}
+		spurious = nil
+	}
+	dropPolicy := role.DropPolicy{
+		Disable:     conf.Postgres.DropPolicy == "disable",
+		GracePeriod: conf.Postgres.DropGracePeriod,
+		Now:         time.Now(),
+		ReassignTo:  conf.Postgres.ReassignTo,
+		BeforeDrop:  conf.Postgres.BeforeDrop,
+		AfterDrop:   conf.Postgres.AfterDrop,
+		KeepParents: skipRemovals,
+	}
+	// Don't count disabled roles waiting for grace period.
+	spurious = slices.DeleteFunc(spurious, func(name string) bool {
+		return dropPolicy.Waiting(instance.AllRoles[name])
+	})
+	if conf.Postgres.OwnedObjects == "refuse" {
+		var drops []string
+		for _, name := range spurious {
+			if dropPolicy.Drops(instance.AllRoles[name]) {
+				drops = append(drops, name)
+			}
+		}
+		owned, err := inspect.InspectOwnedObjects(ctx, drops)
+		if err != nil {
+			return fmt.Errorf("owned objects: %w", err)
+		}
+		managed, spurious, err = refuseOwners(owned, managed, spurious)
+		if !syncErrors.Append(err) {
+			return syncErrors.Value()
+		}
+	}
+	err = controller.checkThreshold("max_dropped_roles", len(spurious), conf.Postgres.MaxDroppedRoles.Limit(len(instance.ManagedRoles)))
+	if err != nil {
+		return
+	}
+	// Get the effective list of managed roles.
+	managedRoles := mapset.NewSet(slices.Collect(maps.Keys(wantedRoles))...)
+	_, ok := instance.ManagedRoles["public"]
+	if ok {
+		managedRoles.Add("public")
+	}
+
+	if conf.ArePrivilegesManaged() && conf.Postgres.MaxRevokedGrants.Value >= 0 && !skipRemovals {
+		// Count revokes over all databases and ACLs before any change.
+		inspected, revokes := 0, 0
+		err = inspectPrivileges(ctx, &instance, pc, managedRoles, func(dbname, acl string, grants []privileges.Grant) {
+			database := postgres.Databases[dbname]
+			grants = privileges.Managed(acl, grants, database, wantedGrants)
+			inspected += len(grants)
+			revokes += len(privileges.Revokes(grants, privileges.Expand(wantedGrants[acl], database)))
+		})
+		if err != nil {
+			return
+		}
+		err = controller.checkThreshold("max_revoked_grants", revokes, conf.Postgres.MaxRevokedGrants.Limit(inspected))
+		if err != nil {
+			return
+		}
+	}
+	hookCount, err := runHooks(ctx, "pre sync", conf.Postgres.PreSync, instance.DefaultDatabase, controller.Real)
+	if !syncErrors.Append(err) || aborts(err) {
+		return syncErrors.Value()
+	}
+	queries := role.Diff(instance.AllRoles, managed, wantedRoles, instance.FallbackOwner, dropPolicy)
 	queries = postgres.GroupByDatabase(instance.DefaultDatabase, queries)
+	postgres.CurrentPlan.Phase("roles")
 	stageCount, err := postgres.Apply(ctx, queries, controller.Real)
-	if !syncErrors.Append(err) {
+	countChanges("roles", stageCount)
+	if !syncErrors.Append(err) || aborts(err) {
 		return syncErrors.Value()
 	}
 	if stageCount == 0 {
 		slog.Info("All roles synchronized.")
 	}
-	queryCount := stageCount
+	queryCount := hookCount + stageCount
 
 	// Synchronize privileges.
 	if conf.ArePrivilegesManaged() {
 		slog.Debug("Synchronizing privileges.")
-		// Get the effective list of managed roles.
-		managedRoles := mapset.NewSet(slices.Collect(maps.Keys(wantedRoles))...)
-		_, ok := instance.ManagedRoles["public"]
-		if ok {
-			managedRoles.Add("public")
-		}
-
 		instanceACLs, databaseACLs, defaultACLs := privileges.SplitManagedACLs()
 
 		// Start by default database. This allow to reuse the last
@@ -130,6 +260,12 @@ func ldap2pg() (err error) {
 			if err != nil {
 				return fmt.Errorf("inspect: %w", err)
 			}
+			if privileges.ObjectsManaged() {
+				err = instance.InspectObjects(ctx, dbname)
+				if err != nil {
+					return fmt.Errorf("inspect: %w", err)
+				}
+			}
 			var acls []string
 			if dbname == instance.DefaultDatabase && len(instanceACLs) > 0 {
 				slog.Debug("Managing instance wide privileges.", "database", dbname)
@@ -137,8 +273,10 @@ func ldap2pg() (err error) {
 			}
 			acls = append(acls, databaseACLs...)
 
-			stageCount, err := syncPrivileges(ctx, &controller, managedRoles, wantedGrants, dbname, acls)
-			if !syncErrors.Append(err) {
+			postgres.CurrentPlan.Phase("privileges")
+			stageCount, err := syncPrivileges(ctx, &controller, managedRoles, wantedGrants, dbname, acls, !skipRemovals, &fingerprint)
+			countChanges("privileges", stageCount)
+			if !syncErrors.Append(err) || aborts(err) {
 				return fmt.Errorf("stage 2: %w", syncErrors.Value())
 			}
 			if stageCount == 0 {
@@ -155,8 +293,10 @@ func ldap2pg() (err error) {
 			if err != nil {
 				return fmt.Errorf("inspect: %w", err)
 			}
-			stageCount, err = syncPrivileges(ctx, &controller, managedRoles, wantedGrants, dbname, defaultACLs)
-			if !syncErrors.Append(err) {
+			postgres.CurrentPlan.Phase("default privileges")
+			stageCount, err = syncPrivileges(ctx, &controller, managedRoles, wantedGrants, dbname, defaultACLs, !skipRemovals, &fingerprint)
+			countChanges("default privileges", stageCount)
+			if !syncErrors.Append(err) || aborts(err) {
 				return fmt.Errorf("stage 3: %w", syncErrors.Value())
 			}
 			if stageCount == 0 {
@@ -168,10 +308,31 @@ func ldap2pg() (err error) {
 		slog.Debug("Not synchronizing privileges.")
 	}
 
+	hookCount, err = runHooks(ctx, "post sync", conf.Postgres.PostSync, instance.DefaultDatabase, controller.Real)
+	queryCount += hookCount
+	if !syncErrors.Append(err) || aborts(err) {
+		return syncErrors.Value()
+	}
+
 	grantCount := 0
 	for _, grants := range wantedGrants {
 		grantCount += len(grants)
 	}
+
+	if !syncErrors.Append(postgres.Commit(ctx)) {
+		return syncErrors.Value()
+	}
+
+	if postgres.CurrentPlan != nil {
+		postgres.CurrentPlan.Fingerprint = fingerprint.Sum()
+		postgres.CurrentPlan.ManagedRoles = managedRoles.ToSlice()
+		slices.Sort(postgres.CurrentPlan.ManagedRoles)
+	}
+	err = writePlan(controller.PlanFormat, controller.Output)
+	if !syncErrors.Append(err) {
+		return syncErrors.Value()
+	}
+	postgres.Unlock(ctx)
 	return controller.Finalize(
 		syncErrors,
 		start,
@@ -214,27 +375,11 @@ func configure() (controller Controller, c config.Config, err error) {
 		return
 	}
 
-	configPath := config.FindConfigFile(controller.Config)
-	if configPath == "" {
-		err = fmt.Errorf("no configuration file found")
-		return
-	}
-
-	slog.Info("Using YAML configuration file.", "path", configPath)
-	c, err = config.Load(configPath)
+	c, configPath, err := loadConfig(controller)
 	if err != nil {
 		return
 	}
 
-	if controller.SkipPrivileges {
-		c.DropPrivileges()
-	} else {
-		err = c.RegisterPrivileges()
-		if err != nil {
-			return
-		}
-	}
-
 	envpath := config.FindDotEnvFile(configPath)
 	if envpath != "" {
 		slog.Debug("Loading .env file.", "path", envpath)
@@ -245,7 +390,11 @@ func configure() (controller Controller, c config.Config, err error) {
 		}
 	}
 
-	if controller.Real {
+	postgres.TransactionMode = controller.Transaction
+
+	if controller.Command == "apply" {
+		slog.Info("Applying plan. Postgres instance will be modified.", "path", controller.PlanFile)
+	} else if controller.Real {
 		slog.Info("Real mode. Postgres instance will be modified.")
 	} else {
 		slog.Warn("Dry run. Postgres instance will be untouched.")
@@ -266,8 +415,106 @@ func configure() (controller Controller, c config.Config, err error) {
 	return
 }
 
+// loadConfig loads YAML configuration file and registers privileges.
+func loadConfig(controller Controller) (c config.Config, path string, err error) {
+	path = config.FindConfigFile(controller.Config)
+	if path == "" {
+		err = fmt.Errorf("no configuration file found")
+		return
+	}
+
+	slog.Info("Using YAML configuration file.", "path", path)
+	c, err = config.Load(path)
+	if err != nil {
+		return
+	}
+
+	if controller.SkipPrivileges {
+		c.DropPrivileges()
+	} else {
+		err = c.RegisterPrivileges()
+	}
+	return
+}
+
+// runHooks runs SQL hooks once per managed database, default database first.
+func runHooks(ctx context.Context, phase string, hooks []string, defaultDatabase string, really bool) (int, error) {
+	if len(hooks) == 0 {
+		return 0, nil
+	}
+	databases := postgres.SyncOrder(defaultDatabase, true)
+	queries := make(chan postgres.SyncQuery, len(databases)*len(hooks))
+	for _, dbname := range databases {
+		for _, sql := range hooks {
+			queries <- postgres.SyncQuery{
+				Description: "Run hook.",
+				LogArgs:     []any{"hook", phase},
+				Database:    dbname,
+				// Protect SQL from formatting.
+				Query: strings.ReplaceAll(sql, "%", "%%"),
+			}
+		}
+	}
+	close(queries)
+	postgres.CurrentPlan.Phase(phase)
+	count, err := postgres.Apply(ctx, queries, really)
+	countChanges(phase, count)
+	return count, err
+}
+
+// refuseOwners keeps roles owning objects instead of dropping them.
+//
+// Returns managed and spurious roles without owners, and an error listing
+// owners.
+func refuseOwners(owned map[string][]inspect.OwnedObject, managed role.Map, spurious []string) (role.Map, []string, error) {
+	if len(owned) == 0 {
+		return managed, spurious, nil
+	}
+
+	var errs []error
+	managed = maps.Clone(managed)
+	for _, name := range slices.Sorted(maps.Keys(owned)) {
+		objects := owned[name]
+		slog.Error("Refusing to drop role owning objects.", "role", name, "count", len(objects))
+		for _, o := range objects {
+			slog.Error("Object owned by role.", "role", name, "object", o.Object, "database", o.Database)
+		}
+		delete(managed, name)
+		errs = append(errs, fmt.Errorf("role %s owns %d objects", name, len(objects)))
+	}
+	spurious = slices.DeleteFunc(spurious, func(name string) bool {
+		_, ok := owned[name]
+		return ok
+	})
+	return managed, spurious, errors.Join(errs...)
+}
+
+// lockError exits with code 3 if another ldap2pg holds the lock.
+func lockError(err error) error {
+	if !errors.Is(err, postgres.ErrLocked) {
+		return err
+	}
+	slog.Error(err.Error())
+	return errorCode{code: 3, message: err.Error()}
+}
+
+// aborts returns whether err stops synchronization.
+//
+// This is the case when all changes are rolled back, on too many changes or
+// on interruption.
+func aborts(err error) bool {
+	if _, ok := errors.AsType[thresholdError](err); ok {
+		return true
+	}
+	if errors.Is(err, postgres.ErrInterrupted) {
+		return true
+	}
+	_, ok := errors.AsType[postgres.RollbackError](err)
+	return ok && postgres.TransactionMode == "all"
+}
+
 // syncPrivileges for a given database.
-func syncPrivileges(ctx context.Context, controller *Controller, roles mapset.Set[string], allWantedGrants map[string][]privileges.Grant, dbname string, acls []string) (int, error) {
+func syncPrivileges(ctx context.Context, controller *Controller, roles mapset.Set[string], allWantedGrants map[string][]privileges.Grant, dbname string, acls []string, revoke bool, fingerprint *inspect.Fingerprint) (int, error) {
 	queryCount := 0
 	var errs []error
 	// synchronize ACL one at a time
@@ -278,7 +525,12 @@ func syncPrivileges(ctx context.Context, controller *Controller, roles mapset.Se
 			errs = append(errs, fmt.Errorf("inspect: %w", err))
 			continue
 		}
-		count, err := privileges.Sync(ctx, controller.Real, dbname, currentGrants, allWantedGrants[acl])
+		fingerprint.AddGrants(dbname, currentGrants)
+		database := postgres.Databases[dbname]
+		currentGrants = privileges.Managed(acl, currentGrants, database, allWantedGrants)
+		wantedGrants := privileges.Expand(allWantedGrants[acl], database)
+
+		count, err := privileges.Sync(ctx, controller.Real, revoke, currentGrants, wantedGrants)
 		queryCount += count
 		if err != nil {
 			slog.Error("Failed to synchronize privileges", "acl", acl, "database", dbname, "err", err)
@@ -293,6 +545,38 @@ func syncPrivileges(ctx context.Context, controller *Controller, roles mapset.Se
 	return queryCount, nil
 }
 
+// writePlan to output file or standard output in the requested format.
+func writePlan(format, output string) (err error) {
+	if format == "" {
+		return
+	}
+
+	w := os.Stdout
+	if output != "" && output != "-" {
+		slog.Debug("Writing plan to file.", "path", output, "format", format)
+		w, err = os.Create(output)
+		if err != nil {
+			return fmt.Errorf("plan: %w", err)
+		}
+		defer w.Close() //nolint:errcheck
+	} else {
+		slog.Debug("Writing plan to stdout.", "format", format)
+	}
+
+	switch format {
+	case "json":
+		err = postgres.CurrentPlan.WriteJSON(w)
+	case "sql":
+		err = postgres.CurrentPlan.WriteSQL(w)
+	default:
+		err = fmt.Errorf("unknown format: %s", format)
+	}
+	if err != nil {
+		return fmt.Errorf("plan: %w", err)
+	}
+	return
+}
+
 func logPanic() {
 	r := recover()
 	if r == nil {
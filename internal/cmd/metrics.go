@@ -0,0 +1,162 @@
+package cmd
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/dalibo/ldap2pg/v6/internal/inspect"
+	"github.com/dalibo/ldap2pg/v6/internal/ldap"
+	"github.com/dalibo/ldap2pg/v6/internal/perf"
+	"github.com/dalibo/ldap2pg/v6/internal/postgres"
+)
+
+// metrics holds statistics of the current run.
+var metrics = runMetrics{Changes: map[string]int{}}
+
+type runMetrics struct {
+	Start   time.Time
+	Real    bool
+	Success bool
+	Roles   int
+	Grants  int
+	Queries int
+	// Changes counts queries by phase: roles, privileges and default privileges.
+	Changes map[string]int
+	// LastSuccess is the end of last successful run. Zero if unknown.
+	LastSuccess time.Time
+
+	// Set by collect.
+	End      time.Time
+	Searches int
+	LDAP     time.Duration
+	Inspect  time.Duration
+	Sync     time.Duration
+	MemPeak  int
+}
+
+// collect returns a copy of metrics with timings of the run.
+func (m runMetrics) collect() runMetrics {
+	m.End = time.Now()
+	if m.Success {
+		m.LastSuccess = m.End
+	}
+	m.Searches = ldap.Watch.Count
+	m.LDAP = ldap.Watch.Total
+	m.Inspect = inspect.Watch.Total
+	m.Sync = postgres.Watch.Total
+	m.MemPeak = perf.ReadMemoryWatermark()
+	return m
+}
+
+const lastSuccessMetric = "ldap2pg_last_success_timestamp_seconds"
+
+// WriteProm renders collected metrics in Prometheus text exposition format.
+func (m runMetrics) WriteProm(w io.Writer) error {
+	b := strings.Builder{}
+	gauge := func(name, help string, samples ...string) {
+		fmt.Fprintf(&b, "# HELP %s %s\n# TYPE %s gauge\n", name, help, name)
+		for _, s := range samples {
+			fmt.Fprintf(&b, "%s%s\n", name, s)
+		}
+	}
+	value := func(v any) string {
+		switch v := v.(type) {
+		case bool:
+			if v {
+				return " 1"
+			}
+			return " 0"
+		case time.Duration:
+			return " " + strconv.FormatFloat(v.Seconds(), 'f', -1, 64)
+		case time.Time:
+			return " " + strconv.FormatInt(v.Unix(), 10)
+		}
+		return fmt.Sprintf(" %v", v)
+	}
+
+	gauge("ldap2pg_success", "Whether last run succeeded.", value(m.Success))
+	gauge("ldap2pg_real_mode", "Whether last run modified Postgres.", value(m.Real))
+	gauge("ldap2pg_last_run_timestamp_seconds", "End of last run.", value(m.End))
+	if !m.LastSuccess.IsZero() {
+		gauge(lastSuccessMetric, "End of last successful run.", value(m.LastSuccess))
+	}
+	gauge("ldap2pg_searches", "Number of LDAP searches.", value(m.Searches))
+	gauge("ldap2pg_roles", "Number of wanted roles.", value(m.Roles))
+	if m.Grants >= 0 {
+		gauge("ldap2pg_grants", "Number of wanted grants.", value(m.Grants))
+	}
+	gauge("ldap2pg_queries", "Number of queries executed, or to execute in dry mode.", value(m.Queries))
+	var changes []string
+	for _, phase := range []string{"pre sync", "roles", "privileges", "default privileges", "post sync"} {
+		changes = append(changes, fmt.Sprintf(`{phase=%q}%s`, phase, value(m.Changes[phase])))
+	}
+	gauge("ldap2pg_changes", "Number of queries by synchronization phase.", changes...)
+	gauge("ldap2pg_elapsed_seconds", "Duration of last run.", value(m.End.Sub(m.Start)))
+	gauge("ldap2pg_duration_seconds", "Cumulated duration by kind of operation.",
+		`{operation="ldap"}`+value(m.LDAP),
+		`{operation="inspect"}`+value(m.Inspect),
+		`{operation="sync"}`+value(m.Sync),
+	)
+	gauge("ldap2pg_memory_peak_bytes", "Peak of memory usage.", value(m.MemPeak))
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// writeMetricsFile writes metrics in a node_exporter textfile.
+//
+// Preserves last success timestamp from previous file on failure. Writes to a
+// temporary file then renames it to avoid partial reads.
+func writeMetricsFile(path string) error {
+	m := metrics.collect()
+	if m.LastSuccess.IsZero() {
+		m.LastSuccess = readLastSuccess(path)
+	}
+	tmp := path + ".tmp"
+	fo, err := os.Create(tmp)
+	if err != nil {
+		return err
+	}
+	err = m.WriteProm(fo)
+	cerr := fo.Close()
+	if err == nil {
+		err = cerr
+	}
+	if err != nil {
+		_ = os.Remove(tmp)
+		return err
+	}
+	slog.Debug("Writing metrics.", "path", path)
+	return os.Rename(tmp, path)
+}
+
+// readLastSuccess searches last success timestamp in a metrics file.
+func readLastSuccess(path string) (t time.Time) {
+	fo, err := os.Open(path)
+	if err != nil {
+		return
+	}
+	defer fo.Close() //nolint:errcheck
+	scanner := bufio.NewScanner(fo)
+	for scanner.Scan() {
+		value, found := strings.CutPrefix(scanner.Text(), lastSuccessMetric+" ")
+		if !found {
+			continue
+		}
+		seconds, err := strconv.ParseInt(value, 10, 64)
+		if err == nil {
+			t = time.Unix(seconds, 0)
+		}
+	}
+	return
+}
+
+// countChanges accumulates queries of a synchronization phase.
+func countChanges(phase string, count int) {
+	metrics.Changes[phase] += count
+}
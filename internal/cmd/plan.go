@@ -0,0 +1,155 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"slices"
+	"time"
+
+	"github.com/dalibo/ldap2pg/v6/internal/config"
+	"github.com/dalibo/ldap2pg/v6/internal/errorlist"
+	"github.com/dalibo/ldap2pg/v6/internal/inspect"
+	"github.com/dalibo/ldap2pg/v6/internal/postgres"
+	"github.com/dalibo/ldap2pg/v6/internal/privileges"
+	mapset "github.com/deckarep/golang-set/v2"
+)
+
+// applyPlan executes a saved plan if Postgres is unchanged since planning.
+func applyPlan(ctx context.Context, controller Controller, conf config.Config, start time.Time) error {
+	slog.Debug("Loading plan.", "path", controller.PlanFile)
+	f, err := os.Open(controller.PlanFile)
+	if err != nil {
+		return fmt.Errorf("plan: %w", err)
+	}
+	plan, err := postgres.ReadPlan(f)
+	_ = f.Close()
+	if err != nil {
+		return fmt.Errorf("plan: %w", err)
+	}
+	if plan.Fingerprint == "" {
+		return errors.New("plan: missing fingerprint")
+	}
+	for _, q := range plan.Queries() {
+		if q.Masked {
+			return fmt.Errorf("plan: %s: query has masked secrets, synchronize without plan", q.Description)
+		}
+	}
+	for _, t := range plan.Thresholds {
+		err = controller.checkThreshold(t.Param, t.Count, t.Max)
+		if err != nil {
+			return fmt.Errorf("plan: %w", err)
+		}
+	}
+
+	pc := conf.Postgres.Build()
+	defer postgres.Unlock(ctx)
+	instance, err := inspect.Stage0(ctx, pc)
+	if err != nil {
+		return lockError(err)
+	}
+	err = instance.InspectStage1(ctx, pc)
+	if err != nil {
+		return err
+	}
+	err = postgres.CheckTransactionMode(instance.DefaultDatabase)
+	if err != nil {
+		return err
+	}
+	var fingerprint inspect.Fingerprint
+	fingerprint.AddRoles(instance.AllRoles)
+	if conf.ArePrivilegesManaged() {
+		err = inspectPrivileges(ctx, &instance, pc, mapset.NewSet(plan.ManagedRoles...), func(dbname, _ string, grants []privileges.Grant) {
+			fingerprint.AddGrants(dbname, grants)
+		})
+		if err != nil {
+			return err
+		}
+	}
+	if fingerprint.Sum() != plan.Fingerprint {
+		slog.Error("Postgres instance changed since planning.", "plan", plan.Fingerprint, "instance", fingerprint.Sum())
+		return errors.New("plan is outdated, compute a new plan")
+	}
+	slog.Info("Postgres instance unchanged since planning.", "fingerprint", plan.Fingerprint)
+
+	syncErrors := errorlist.New("synchronization errors")
+	queryCount := 0
+	phases := []struct {
+		name    string
+		queries []postgres.PlannedQuery
+	}{
+		{"pre sync", plan.PreSync},
+		{"roles", plan.Roles},
+		{"privileges", plan.Privileges},
+		{"default privileges", plan.DefaultPrivileges},
+		{"post sync", plan.PostSync},
+	}
+	for _, phase := range phases {
+		count, err := postgres.Apply(ctx, plannedQueries(phase.queries), controller.Real)
+		countChanges(phase.name, count)
+		queryCount += count
+		if !syncErrors.Append(err) || aborts(err) {
+			return syncErrors.Value()
+		}
+	}
+	if !syncErrors.Append(postgres.Commit(ctx)) {
+		return syncErrors.Value()
+	}
+
+	postgres.Unlock(ctx)
+	return controller.Finalize(syncErrors, start, len(plan.ManagedRoles), -1, queryCount)
+}
+
+// inspectPrivileges inspects grants of managed ACLs in each database, like
+// main loop does before synchronizing each database.
+//
+// Calls fn with grants of each ACL in each database.
+func inspectPrivileges(ctx context.Context, instance *inspect.Instance, pc inspect.Config, roles mapset.Set[string], fn func(dbname, acl string, grants []privileges.Grant)) error {
+	instanceACLs, databaseACLs, defaultACLs := privileges.SplitManagedACLs()
+	for _, dbname := range postgres.SyncOrder(instance.DefaultDatabase, true) {
+		err := instance.InspectStage2(ctx, dbname, pc.SchemasQuery)
+		if err != nil {
+			return fmt.Errorf("inspect: %w", err)
+		}
+		if privileges.ObjectsManaged() {
+			err = instance.InspectObjects(ctx, dbname)
+			if err != nil {
+				return fmt.Errorf("inspect: %w", err)
+			}
+		}
+		var acls []string
+		if dbname == instance.DefaultDatabase {
+			acls = slices.Clone(instanceACLs)
+		}
+		acls = append(acls, databaseACLs...)
+		if len(defaultACLs) > 0 {
+			err = instance.InspectStage3(ctx, dbname, roles)
+			if err != nil {
+				return fmt.Errorf("inspect: %w", err)
+			}
+			acls = append(acls, defaultACLs...)
+		}
+
+		for _, acl := range acls {
+			grants, err := privileges.Inspect(ctx, postgres.Databases[dbname], acl, roles)
+			if err != nil {
+				return fmt.Errorf("inspect: %s: %w", acl, err)
+			}
+			fn(dbname, acl, grants)
+		}
+	}
+	return nil
+}
+
+func plannedQueries(in []postgres.PlannedQuery) <-chan postgres.SyncQuery {
+	ch := make(chan postgres.SyncQuery)
+	go func() {
+		defer close(ch)
+		for _, q := range in {
+			ch <- q.SyncQuery()
+		}
+	}()
+	return ch
+}
@@ -6,6 +6,7 @@ import (
 	"log/slog"
 	"os"
 	"path"
+	"time"
 
 	"github.com/dalibo/ldap2pg/v6/internal/ldap"
 	"github.com/dalibo/ldap2pg/v6/internal/postgres"
@@ -84,12 +85,21 @@ type Config struct {
 	ACLs       map[string]privileges.ACL `mapstructure:"acls"`
 	Privileges map[string]privileges.Profile
 	Rules      wanted.Rules `mapstructure:"rules"`
+	Audit      postgres.Audit
 }
 
 // New initiate a config structure with defaults.
 func New() Config {
 	return Config{
 		Postgres: PostgresConfig{
+			// ldap2pg in ASCII.
+			LockKey:          0x6c646170327067,
+			DropPolicy:       "drop",
+			DropGracePeriod:  7 * 24 * time.Hour,
+			OwnedObjects:     "reassign",
+			RoleConflicts:    "first",
+			MaxDroppedRoles:  Threshold{Value: -1},
+			MaxRevokedGrants: Threshold{Value: -1},
 			DatabasesQuery: NewSQLQuery[string](dedent.Dedent(`
 				SELECT datname FROM pg_catalog.pg_database
 				 WHERE datallowconn IS TRUE
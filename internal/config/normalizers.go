@@ -5,10 +5,12 @@ import (
 	"errors"
 	"fmt"
 	"maps"
+	"slices"
 
 	"github.com/dalibo/ldap2pg/v6/internal/ldap"
 	"github.com/dalibo/ldap2pg/v6/internal/normalize"
 	"github.com/dalibo/ldap2pg/v6/internal/privileges"
+	"github.com/dalibo/ldap2pg/v6/internal/pyfmt"
 )
 
 func NormalizeConfigRoot(yaml any) (config map[string]any, err error) {
@@ -25,6 +27,14 @@ func NormalizeConfigRoot(yaml any) (config map[string]any, err error) {
 		}
 	}
 
+	section, ok = config["audit"]
+	if ok {
+		err = NormalizeAudit(section)
+		if err != nil {
+			return config, fmt.Errorf("audit: %w", err)
+		}
+	}
+
 	section, ok = config["acls"]
 	if ok {
 		acls, err := privileges.NormalizeACLs(section)
@@ -60,13 +70,96 @@ func NormalizeConfigRoot(yaml any) (config map[string]any, err error) {
 }
 
 func NormalizePostgres(yaml any) error {
-	_, ok := yaml.(map[string]any)
+	postgres, ok := yaml.(map[string]any)
 	if !ok {
 		return fmt.Errorf("bad type: %T, must be a map", yaml)
 	}
+	if policy, ok := postgres["drop_policy"]; ok && policy != "drop" && policy != "disable" {
+		return fmt.Errorf("drop_policy: bad value: %v", policy)
+	}
+	if value, ok := postgres["owned_objects"]; ok && value != "reassign" && value != "refuse" {
+		return fmt.Errorf("owned_objects: bad value: %v", value)
+	}
+	if value, ok := postgres["role_conflicts"]; ok && !slices.Contains([]any{"error", "first", "last", "permissive"}, value) {
+		return fmt.Errorf("role_conflicts: bad value: %v", value)
+	}
+	if value, ok := postgres["reassign_to"]; ok {
+		s, ok := value.(string)
+		if !ok {
+			return fmt.Errorf("reassign_to: bad type: %T", value)
+		}
+		f, err := pyfmt.Parse(s)
+		if err != nil {
+			return fmt.Errorf("reassign_to: %w", err)
+		}
+		for _, field := range f.Fields {
+			if field.FieldName != "name" && field.FieldName != "parent" {
+				return fmt.Errorf("reassign_to: unknown variable: %s", field.FieldName)
+			}
+		}
+	}
+	for _, key := range []string{"before_drop", "after_drop"} {
+		value, ok := postgres[key]
+		if !ok {
+			continue
+		}
+		hook, err := NormalizeHook(value)
+		if err != nil {
+			return fmt.Errorf("%s: %w", key, err)
+		}
+		// Run before drop hook before reassigning objects in each database.
+		if _, ok := hook["database"]; !ok && key == "before_drop" {
+			hook["database"] = "__all__"
+		}
+		f, err := pyfmt.Parse(fmt.Sprint(hook["sql"]))
+		if err != nil {
+			return fmt.Errorf("%s: %w", key, err)
+		}
+		for _, field := range f.Fields {
+			if !slices.Contains([]string{"name", "comment", "id"}, field.FieldName) {
+				return fmt.Errorf("%s: unknown variable: %s", key, field.FieldName)
+			}
+		}
+		postgres[key] = hook
+	}
+	for _, key := range []string{"pre_sync", "post_sync"} {
+		value, ok := postgres[key]
+		if !ok {
+			continue
+		}
+		hooks, err := normalize.StringList(value)
+		if err != nil {
+			return fmt.Errorf("%s: %w", key, err)
+		}
+		postgres[key] = hooks
+	}
+	if value, ok := postgres["managed_options"]; ok {
+		options, err := normalize.StringList(value)
+		if err != nil {
+			return fmt.Errorf("managed_options: %w", err)
+		}
+		known := defaultRoleOptions()
+		for _, option := range options {
+			if _, ok := known[option]; !ok {
+				return fmt.Errorf("managed_options: unknown option: %s", option)
+			}
+		}
+		postgres["managed_options"] = options
+	}
 	return nil
 }
 
+func NormalizeAudit(yaml any) error {
+	audit, ok := yaml.(map[string]any)
+	if !ok {
+		return fmt.Errorf("bad type: %T, must be a map", yaml)
+	}
+	if _, ok := audit["table"]; !ok {
+		return errors.New("missing table")
+	}
+	return normalize.SpuriousKeys(audit, "table", "database")
+}
+
 func NormalizeRules(yaml any) (syncMap []any, err error) {
 	rawRules, ok := yaml.([]any)
 	if !ok {
@@ -156,7 +249,7 @@ func NormalizeLdapSearch(yaml any) (search map[string]any, err error) {
 	if err != nil {
 		return
 	}
-	err = normalize.SpuriousKeys(search, "base", "filter", "scope", "subsearches", "on_unexpected_dn")
+	err = normalize.SpuriousKeys(search, "base", "filter", "scope", "subsearches", "on_unexpected_dn", "min_entries", "max_entries")
 	if err != nil {
 		return
 	}
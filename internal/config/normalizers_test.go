@@ -93,3 +93,49 @@ func TestNormalizeCommonLdapSearch(t *testing.T) {
 	r.Nil(err)
 	r.Equal(search["filter"], "(cn=test)")
 }
+
+func TestNormalizeAudit(t *testing.T) {
+	r := require.New(t)
+
+	rawYaml := dedent.Dedent(`
+	table: ldap2pg.audit
+	database: admin
+	`)
+	var raw any
+	yaml.Unmarshal([]byte(rawYaml), &raw) //nolint:errcheck
+	r.Nil(config.NormalizeAudit(raw))
+
+	r.ErrorContains(config.NormalizeAudit(map[string]any{"database": "admin"}), "missing table")
+	r.ErrorContains(config.NormalizeAudit("ldap2pg.audit"), "bad type")
+}
+
+func TestNormalizePostgres(t *testing.T) {
+	r := require.New(t)
+
+	r.Nil(config.NormalizePostgres(map[string]any{"drop_policy": "disable"}))
+	err := config.NormalizePostgres(map[string]any{"drop_policy": "archive"})
+	r.ErrorContains(err, "drop_policy: bad value")
+
+	r.Nil(config.NormalizePostgres(map[string]any{"owned_objects": "refuse", "reassign_to": "{parent}_owner"}))
+	err = config.NormalizePostgres(map[string]any{"owned_objects": "drop"})
+	r.ErrorContains(err, "owned_objects: bad value")
+	err = config.NormalizePostgres(map[string]any{"reassign_to": "{member}_owner"})
+	r.ErrorContains(err, "unknown variable: member")
+
+	raw := map[string]any{"managed_options": "SUPERUSER"}
+	r.Nil(config.NormalizePostgres(raw))
+	r.Equal([]string{"SUPERUSER"}, raw["managed_options"])
+	err = config.NormalizePostgres(map[string]any{"managed_options": []any{"LOGIN", "PASSWORD"}})
+	r.ErrorContains(err, "unknown option: PASSWORD")
+
+	raw = map[string]any{"before_drop": "DROP SCHEMA {name} CASCADE;", "after_drop": "SELECT 1;"}
+	r.Nil(config.NormalizePostgres(raw))
+	r.Equal(map[string]any{"sql": "DROP SCHEMA {name} CASCADE;", "database": "__all__"}, raw["before_drop"])
+	r.Equal(map[string]any{"sql": "SELECT 1;"}, raw["after_drop"])
+	err = config.NormalizePostgres(map[string]any{"after_drop": "SELECT {cn};"})
+	r.ErrorContains(err, "after_drop: unknown variable: cn")
+
+	raw = map[string]any{"pre_sync": "SET lock_timeout TO '5s';"}
+	r.Nil(config.NormalizePostgres(raw))
+	r.Equal([]string{"SET lock_timeout TO '5s';"}, raw["pre_sync"])
+}
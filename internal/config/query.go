@@ -3,9 +3,12 @@ package config
 import (
 	"fmt"
 	"strings"
+	"time"
 
 	"github.com/dalibo/ldap2pg/v6/internal/inspect"
 	"github.com/dalibo/ldap2pg/v6/internal/postgres"
+	"github.com/dalibo/ldap2pg/v6/internal/pyfmt"
+	"github.com/dalibo/ldap2pg/v6/internal/role"
 	"github.com/jackc/pgx/v5"
 	"github.com/lithammer/dedent"
 )
@@ -17,6 +20,20 @@ import (
 // final inspect.Config object.
 type PostgresConfig struct {
 	FallbackOwner       string                       `mapstructure:"fallback_owner"`
+	DropPolicy          string                       `mapstructure:"drop_policy"`
+	DropGracePeriod     time.Duration                `mapstructure:"drop_grace_period"`
+	OwnedObjects        string                       `mapstructure:"owned_objects"`
+	ReassignTo          pyfmt.Format                 `mapstructure:"reassign_to"`
+	BeforeDrop          role.HookFormat              `mapstructure:"before_drop"`
+	AfterDrop           role.HookFormat              `mapstructure:"after_drop"`
+	ManagedOptions      []string                     `mapstructure:"managed_options"`
+	RoleConflicts       string                       `mapstructure:"role_conflicts"`
+	PreSync             []string                     `mapstructure:"pre_sync"`
+	PostSync            []string                     `mapstructure:"post_sync"`
+	LockKey             int64                        `mapstructure:"lock_key"`
+	LockTimeout         time.Duration                `mapstructure:"lock_timeout"`
+	MaxDroppedRoles     Threshold                    `mapstructure:"max_dropped_roles"`
+	MaxRevokedGrants    Threshold                    `mapstructure:"max_revoked_grants"`
 	DatabasesQuery      QueryConfig[string]          `mapstructure:"databases_query"`
 	ManagedRolesQuery   QueryConfig[string]          `mapstructure:"managed_roles_query"`
 	RolesBlacklistQuery QueryConfig[string]          `mapstructure:"roles_blacklist_query"`
@@ -26,6 +43,8 @@ type PostgresConfig struct {
 func (c PostgresConfig) Build() inspect.Config {
 	ic := inspect.Config{
 		FallbackOwner:       c.FallbackOwner,
+		LockKey:             c.LockKey,
+		LockTimeout:         c.LockTimeout,
 		DatabasesQuery:      c.DatabasesQuery.Querier,
 		ManagedRolesQuery:   c.ManagedRolesQuery.Querier,
 		RolesBlacklistQuery: c.RolesBlacklistQuery.Querier,
@@ -8,6 +8,10 @@ import (
 	"strings"
 
 	"github.com/dalibo/ldap2pg/v6/internal/normalize"
+	"github.com/dalibo/ldap2pg/v6/internal/pyfmt"
+	"github.com/dalibo/ldap2pg/v6/internal/role"
+	"github.com/dalibo/ldap2pg/v6/internal/wanted"
+	"github.com/go-viper/mapstructure/v2"
 )
 
 func NormalizeRoleRule(yaml any) (rule map[string]any, err error) {
@@ -49,11 +53,29 @@ func NormalizeRoleRule(yaml any) (rule map[string]any, err error) {
 		if err != nil {
 			return nil, fmt.Errorf("options: %w", err)
 		}
+		for _, key := range []string{"before_create", "after_create", "after_alter"} {
+			hook, ok := rule[key]
+			if !ok {
+				continue
+			}
+			rule[key], err = NormalizeHook(hook)
+			if err != nil {
+				return nil, fmt.Errorf("%s: %w", key, err)
+			}
+		}
+		if rule["password"] == nil {
+			delete(rule, "password")
+		} else {
+			rule["password"], err = NormalizePassword(rule["password"])
+			if err != nil {
+				return nil, fmt.Errorf("password: %w", err)
+			}
+		}
 	default:
 		return nil, fmt.Errorf("bad type: %T", yaml)
 	}
 
-	err = normalize.SpuriousKeys(rule, "names", "comment", "parents", "options", "config", "before_create", "after_create")
+	err = normalize.SpuriousKeys(rule, "names", "comment", "id", "parents", "options", "config", "database_config", "security_labels", "password", "valid_until", "before_create", "after_create", "after_alter")
 	return
 }
 
@@ -74,10 +96,8 @@ func DuplicateRoleRules(yaml map[string]any) (rules []map[string]any) {
 	return
 }
 
-func NormalizeRoleOptions(yaml any) (value map[string]any, err error) {
-	// Normal form of role options is a map with SQL token as key and
-	// boolean or int value.
-	value = map[string]any{
+func defaultRoleOptions() map[string]any {
+	return map[string]any{
 		"SUPERUSER":        false,
 		"INHERIT":          true,
 		"CREATEROLE":       false,
@@ -87,7 +107,13 @@ func NormalizeRoleOptions(yaml any) (value map[string]any, err error) {
 		"BYPASSRLS":        false,
 		"CONNECTION LIMIT": -1,
 	}
-	knownKeys := slices.Collect(maps.Keys(value))
+}
+
+func NormalizeRoleOptions(yaml any) (value map[string]any, err error) {
+	// Normal form of role options is a map with SQL token as key and
+	// boolean or int value. Omitted options are unmanaged.
+	value = map[string]any{}
+	knownKeys := slices.Collect(maps.Keys(defaultRoleOptions()))
 
 	switch yaml := yaml.(type) {
 	case string:
@@ -113,6 +139,106 @@ func NormalizeRoleOptions(yaml any) (value map[string]any, err error) {
 	return
 }
 
+// decodeOptionsRule splits static options from options formatted with LDAP
+// attributes.
+//
+// Omitted options have default value for role creation and are unmanaged.
+func decodeOptionsRule(yaml any) (rule wanted.OptionsRule, err error) {
+	values, ok := yaml.(map[string]any)
+	if !ok {
+		return rule, fmt.Errorf("bad type: %T", yaml)
+	}
+	defaults := defaultRoleOptions()
+	static := defaultRoleOptions()
+	// Non-nil to manage no option of a rule without options.
+	rule.Managed = append([]string{}, slices.Sorted(maps.Keys(values))...)
+	for k, v := range values {
+		s, ok := v.(string)
+		if !ok || !strings.Contains(s, "{") {
+			static[k] = v
+			continue
+		}
+		// Fallback to default on missing attribute.
+		static[k] = defaults[k]
+		f, err := pyfmt.Parse(s)
+		if err != nil {
+			return rule, fmt.Errorf("%s: %w", k, err)
+		}
+		if rule.Formats == nil {
+			rule.Formats = map[string]pyfmt.Format{}
+		}
+		rule.Formats[k] = f
+	}
+	d, err := mapstructure.NewDecoder(&mapstructure.DecoderConfig{
+		Result:           &rule.Options,
+		WeaklyTypedInput: true,
+		ErrorUnused:      true,
+	})
+	if err != nil {
+		return
+	}
+	err = d.Decode(static)
+	return
+}
+
+// NormalizeHook normalizes hook to a map with sql and database.
+//
+// A string is SQL run in default database.
+func NormalizeHook(raw any) (value map[string]any, err error) {
+	switch raw := raw.(type) {
+	case string:
+		value = map[string]any{"sql": raw}
+	case map[string]any:
+		value = raw
+		if _, ok := value["sql"]; !ok {
+			return nil, errors.New("missing sql")
+		}
+	default:
+		return nil, fmt.Errorf("bad type: %T", raw)
+	}
+	err = normalize.SpuriousKeys(value, "sql", "database")
+	return
+}
+
+// NormalizePassword normalizes password to a map with value and policy.
+//
+// A string is a value set on role creation. A null policy requires no value.
+func NormalizePassword(raw any) (value map[string]any, err error) {
+	value = map[string]any{"policy": "on_create"}
+	switch raw := raw.(type) {
+	case string:
+		value["value"] = raw
+	case map[string]any:
+		maps.Copy(value, raw)
+	default:
+		return nil, fmt.Errorf("bad type: %T", raw)
+	}
+
+	if value["policy"] == nil {
+		// YAML null.
+		value["policy"] = "null"
+	}
+	switch value["policy"] {
+	case "on_create", "always":
+		v, ok := value["value"].(string)
+		if !ok || v == "" {
+			return nil, errors.New("missing value")
+		}
+		if !strings.Contains(v, "{") && !role.IsVerifier(v) {
+			return nil, errors.New("value is not a SCRAM-SHA-256 verifier")
+		}
+	case "null":
+		if _, ok := value["value"]; ok {
+			return nil, errors.New("value conflicts with null policy")
+		}
+	default:
+		return nil, fmt.Errorf("bad policy: %v", value["policy"])
+	}
+
+	err = normalize.SpuriousKeys(value, "value", "policy")
+	return
+}
+
 func NormalizeMemberships(raw any) (memberships []map[string]any, err error) {
 	list := normalize.List(raw)
 	memberships = make([]map[string]any, 0, len(list))
@@ -128,7 +254,6 @@ func NormalizeMemberships(raw any) (memberships []map[string]any, err error) {
 
 func NormalizeMembership(raw any) (value map[string]any, err error) {
 	value = make(map[string]any)
-	// We could add admin, inherit and set to the map
 
 	switch raw := raw.(type) {
 	case string:
@@ -66,6 +66,8 @@ func TestRoleOptionsString(t *testing.T) {
 	r.Nil(err)
 	r.True(value["SUPERUSER"].(bool))
 	r.True(value["LOGIN"].(bool))
+	// Omitted options are unmanaged.
+	r.NotContains(value, "CREATEDB")
 }
 
 func TestRoleParents(t *testing.T) {
@@ -99,4 +101,58 @@ func TestMembership(t *testing.T) {
 	membership, err = config.NormalizeMembership(raw)
 	r.Nil(err)
 	r.Equal("owners", membership["name"])
+
+	rawYaml = dedent.Dedent(`
+	name: owners
+	inherit: no
+	set: yes
+	`)
+	yaml.Unmarshal([]byte(rawYaml), &raw) //nolint:errcheck
+
+	membership, err = config.NormalizeMembership(raw)
+	r.Nil(err)
+	r.Equal("false", membership["inherit"])
+	r.Equal("true", membership["set"])
+}
+
+func TestPassword(t *testing.T) {
+	r := require.New(t)
+
+	password, err := config.NormalizePassword("{userPassword}")
+	r.Nil(err)
+	r.Equal("on_create", password["policy"])
+	r.Equal("{userPassword}", password["value"])
+
+	rawYaml := dedent.Dedent(`
+	policy: null
+	`)
+	var raw any
+	yaml.Unmarshal([]byte(rawYaml), &raw) //nolint:errcheck
+	password, err = config.NormalizePassword(raw)
+	r.Nil(err)
+	r.Equal("null", password["policy"])
+
+	_, err = config.NormalizePassword(map[string]any{"policy": "always"})
+	r.ErrorContains(err, "missing value")
+
+	_, err = config.NormalizePassword(map[string]any{"policy": "always", "value": "secret"})
+	r.ErrorContains(err, "not a SCRAM-SHA-256 verifier")
+
+	_, err = config.NormalizePassword(map[string]any{"policy": "never", "value": "{userPassword}"})
+	r.ErrorContains(err, "bad policy")
+}
+
+func TestNormalizeHook(t *testing.T) {
+	r := require.New(t)
+
+	value, err := config.NormalizeHook("SELECT 1;")
+	r.Nil(err)
+	r.Equal(map[string]any{"sql": "SELECT 1;"}, value)
+
+	value, err = config.NormalizeHook(map[string]any{"sql": "SELECT 1;", "database": "app"})
+	r.Nil(err)
+	r.Equal("app", value["database"])
+
+	_, err = config.NormalizeHook(map[string]any{"database": "app"})
+	r.ErrorContains(err, "missing sql")
 }
@@ -0,0 +1,56 @@
+package config
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Threshold limits a count of changes, absolute or relative to a total.
+//
+// A negative value means no limit.
+type Threshold struct {
+	Value   int
+	Percent bool
+}
+
+// ParseThreshold parses a number like 10 or a percentage like 10%.
+func ParseThreshold(s string) (t Threshold, err error) {
+	s = strings.TrimSpace(s)
+	t.Percent = strings.HasSuffix(s, "%")
+	t.Value, err = strconv.Atoi(strings.TrimSpace(strings.TrimSuffix(s, "%")))
+	if err != nil {
+		return t, fmt.Errorf("bad threshold: %s", s)
+	}
+	if t.Value < 0 || t.Percent && t.Value > 100 {
+		return t, fmt.Errorf("threshold out of range: %s", s)
+	}
+	return
+}
+
+// Limit returns the maximum count of changes over total. Returns -1 for no
+// limit.
+func (t Threshold) Limit(total int) int {
+	if t.Value < 0 {
+		return -1
+	}
+	if t.Percent {
+		return total * t.Value / 100
+	}
+	return t.Value
+}
+
+func (t Threshold) String() string {
+	if t.Percent {
+		return fmt.Sprintf("%d%%", t.Value)
+	}
+	return strconv.Itoa(t.Value)
+}
+
+// MarshalYAML renders threshold as written in configuration.
+func (t Threshold) MarshalYAML() (any, error) {
+	if t.Value < 0 {
+		return nil, nil
+	}
+	return t.String(), nil
+}
@@ -0,0 +1,30 @@
+package config_test
+
+import (
+	"testing"
+
+	"github.com/dalibo/ldap2pg/v6/internal/config"
+	"github.com/stretchr/testify/require"
+)
+
+func TestThreshold(t *testing.T) {
+	r := require.New(t)
+
+	th, err := config.ParseThreshold("10")
+	r.Nil(err)
+	r.Equal(10, th.Limit(1000))
+	r.Equal("10", th.String())
+
+	th, err = config.ParseThreshold("10%")
+	r.Nil(err)
+	r.Equal(100, th.Limit(1000))
+	r.Equal(0, th.Limit(5))
+	r.Equal("10%", th.String())
+
+	r.Equal(-1, config.Threshold{Value: -1}.Limit(1000))
+
+	_, err = config.ParseThreshold("ten")
+	r.ErrorContains(err, "bad threshold")
+	_, err = config.ParseThreshold("120%")
+	r.ErrorContains(err, "out of range")
+}
@@ -8,12 +8,15 @@ import (
 	"log/slog"
 	"os"
 	"reflect"
+	"regexp"
 	"strings"
+	"time"
 
 	"github.com/dalibo/ldap2pg/v6/internal/errorlist"
 	"github.com/dalibo/ldap2pg/v6/internal/ldap"
 	"github.com/dalibo/ldap2pg/v6/internal/postgres"
 	"github.com/dalibo/ldap2pg/v6/internal/pyfmt"
+	"github.com/dalibo/ldap2pg/v6/internal/wanted"
 	"github.com/go-viper/mapstructure/v2"
 	"github.com/jackc/pgx/v5"
 	"github.com/mattn/go-isatty"
@@ -55,12 +58,18 @@ func (c *Config) LoadYaml(root map[string]any) (err error) {
 			return
 		}
 		item.ReplaceAttributeAsSubentryField()
+		for j := range item.RoleRules {
+			item.RoleRules[j].Options.Manage(c.Postgres.ManagedOptions...)
+		}
 	}
 
 	slog.Debug("Loaded configuration file.", "version", c.Version)
 	return
 }
 
+// verifierRe matches SCRAM-SHA-256 verifiers to mask them in dump.
+var verifierRe = regexp.MustCompile(`SCRAM-SHA-256\$[^\s'"]+`)
+
 func Dump(root any) {
 	var buf bytes.Buffer
 	encoder := yaml.NewEncoder(&buf)
@@ -72,7 +81,7 @@ func Dump(root any) {
 	if color {
 		_, _ = os.Stderr.WriteString("\033[0;2m")
 	}
-	_, _ = os.Stderr.WriteString(buf.String())
+	_, _ = os.Stderr.WriteString(verifierRe.ReplaceAllString(buf.String(), "********"))
 	if color {
 		_, _ = os.Stderr.WriteString("\033[0m")
 	}
@@ -111,7 +120,8 @@ func decodeMapHook(from, to reflect.Value) (any, error) {
 	switch to.Type() {
 	case reflect.TypeOf(pyfmt.Format{}):
 		f := to.Interface().(pyfmt.Format)
-		err := f.Parse(from.String())
+		// Role config accepts numbers like statement_timeout: 3000.
+		err := f.Parse(fmt.Sprint(from.Interface()))
 		if err != nil {
 			return nil, err
 		}
@@ -132,6 +142,18 @@ func decodeMapHook(from, to reflect.Value) (any, error) {
 			return nil, err
 		}
 		return v, nil
+	case reflect.TypeOf(time.Duration(0)):
+		switch from.Kind() {
+		case reflect.String:
+			return time.ParseDuration(from.String())
+		case reflect.Int, reflect.Int64:
+			// Integer is a number of seconds.
+			return time.Duration(from.Int()) * time.Second, nil
+		}
+	case reflect.TypeOf(wanted.OptionsRule{}):
+		return decodeOptionsRule(from.Interface())
+	case reflect.TypeOf(Threshold{}):
+		return ParseThreshold(fmt.Sprint(from.Interface()))
 	case reflect.TypeOf(ldap.Scope(1)):
 		s, err := ldap.ParseScope(from.String())
 		if err != nil {
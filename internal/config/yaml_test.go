@@ -2,9 +2,11 @@ package config_test
 
 import (
 	"testing"
+	"time"
 
 	"github.com/dalibo/ldap2pg/v6/internal/config"
 	"github.com/dalibo/ldap2pg/v6/internal/errorlist"
+	"github.com/dalibo/ldap2pg/v6/internal/role"
 	"github.com/lithammer/dedent"
 	"github.com/stretchr/testify/require"
 	"gopkg.in/yaml.v3"
@@ -55,3 +57,139 @@ func TestStrictYaml(t *testing.T) {
 	r.EqualError(errs[1], "'ldap' has invalid keys: password")
 	r.EqualError(errs[2], "'postgres' has invalid keys: uri")
 }
+
+func TestLoadLock(t *testing.T) {
+	r := require.New(t)
+
+	rawYaml := dedent.Dedent(`
+	postgres:
+	  lock_key: 42
+	  lock_timeout: 1m30s
+	`)
+	var value map[string]any
+	yaml.Unmarshal([]byte(rawYaml), &value) //nolint:errcheck
+
+	c := config.New()
+	err := c.DecodeYaml(value)
+	r.Nil(err)
+	r.Equal(int64(42), c.Postgres.LockKey)
+	r.Equal(90*time.Second, c.Postgres.LockTimeout)
+
+	value["postgres"] = map[string]any{"lock_timeout": 10}
+	err = c.DecodeYaml(value)
+	r.Nil(err)
+	r.Equal(10*time.Second, c.Postgres.LockTimeout)
+}
+
+func TestLoadMembershipOptions(t *testing.T) {
+	r := require.New(t)
+
+	rawYaml := dedent.Dedent(`
+	rules:
+	- role:
+	    name: alice
+	    parents:
+	    - name: owners
+	      inherit: no
+	      set: yes
+	    - readers
+	`)
+	var value any
+	yaml.Unmarshal([]byte(rawYaml), &value) //nolint:errcheck
+	root, err := config.NormalizeConfigRoot(value)
+	r.Nil(err)
+
+	c := config.New()
+	err = c.LoadYaml(root)
+	r.Nil(err)
+	parents := c.Rules[0].RoleRules[0].Parents
+	r.Len(parents, 2)
+	r.NotNil(parents[0].Inherit)
+	r.False(*parents[0].Inherit)
+	r.NotNil(parents[0].Set)
+	r.True(*parents[0].Set)
+	r.Nil(parents[0].Admin)
+	r.Nil(parents[1].Inherit)
+}
+
+func TestLoadHooks(t *testing.T) {
+	r := require.New(t)
+
+	rawYaml := dedent.Dedent(`
+	postgres:
+	  before_drop: "ALTER SCHEMA {name} RENAME TO archive_{name};"
+	rules:
+	- role:
+	    name: alice
+	    after_create: CREATE SCHEMA alice AUTHORIZATION alice;
+	    after_alter:
+	      sql: SELECT 'altered';
+	      database: app
+	`)
+	var value any
+	yaml.Unmarshal([]byte(rawYaml), &value) //nolint:errcheck
+	root, err := config.NormalizeConfigRoot(value)
+	r.Nil(err)
+
+	c := config.New()
+	err = c.LoadYaml(root)
+	r.Nil(err)
+	r.Equal("__all__", c.Postgres.BeforeDrop.Database)
+	rule := c.Rules[0].RoleRules[0]
+	r.Equal("CREATE SCHEMA alice AUTHORIZATION alice;", rule.AfterCreate.SQL.String())
+	r.Equal("", rule.AfterCreate.Database)
+	r.Equal("app", rule.AfterAlter.Database)
+}
+
+func TestLoadRoleWithoutOptions(t *testing.T) {
+	r := require.New(t)
+
+	rawYaml := dedent.Dedent(`
+	rules:
+	- role:
+	    name: alice
+	- role:
+	    name: bob
+	    options: LOGIN
+	`)
+	var value any
+	yaml.Unmarshal([]byte(rawYaml), &value) //nolint:errcheck
+	root, err := config.NormalizeConfigRoot(value)
+	r.Nil(err)
+
+	c := config.New()
+	err = c.LoadYaml(root)
+	r.Nil(err)
+
+	role.ProcessColumns([]string{"rolsuper", "rolcanlogin", "rolconnlimit"}, true)
+	current := role.Options{Super: true, CanLogin: true, ConnLimit: 5}
+
+	alice := c.Rules[0].RoleRules[0].Options.Options
+	r.NotNil(alice.Managed)
+	r.Empty(alice.Managed)
+	r.Equal("", current.Diff(alice))
+
+	bob := c.Rules[1].RoleRules[0].Options.Options
+	r.Equal([]string{"LOGIN"}, bob.Managed)
+	r.Equal("", current.Diff(bob))
+
+	// managed_options applies to rule without options.
+	rawYaml = dedent.Dedent(`
+	postgres:
+	  managed_options: [CONNECTION LIMIT]
+	rules:
+	- role:
+	    name: alice
+	`)
+	value = nil
+	yaml.Unmarshal([]byte(rawYaml), &value) //nolint:errcheck
+	root, err = config.NormalizeConfigRoot(value)
+	r.Nil(err)
+
+	c = config.New()
+	err = c.LoadYaml(root)
+	r.Nil(err)
+	alice = c.Rules[0].RoleRules[0].Options.Options
+	r.Equal([]string{"CONNECTION LIMIT"}, alice.Managed)
+	r.Equal("CONNECTION LIMIT -1", current.Diff(alice))
+}
@@ -1,11 +1,15 @@
 package inspect
 
 import (
+	"time"
+
 	"github.com/dalibo/ldap2pg/v6/internal/postgres"
 )
 
 type Config struct {
 	FallbackOwner       string
+	LockKey             int64
+	LockTimeout         time.Duration
 	DatabasesQuery      Querier[string]
 	ManagedRolesQuery   Querier[string]
 	RolesBlacklistQuery Querier[string]
@@ -0,0 +1,75 @@
+package inspect
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+
+	"github.com/dalibo/ldap2pg/v6/internal/privileges"
+	"github.com/dalibo/ldap2pg/v6/internal/role"
+)
+
+// Fingerprint summarizes inspected state of the instance.
+//
+// Compare fingerprints to detect changes in Postgres between two runs. The sum
+// does not depend on inspection order.
+type Fingerprint struct {
+	lines []string
+}
+
+func (f *Fingerprint) AddRoles(roles role.Map) {
+	for _, name := range slices.Sorted(maps.Keys(roles)) {
+		r := roles[name]
+		var parents []string
+		for _, m := range r.Parents {
+			parents = append(parents, fmt.Sprintf(
+				"%s by %s admin %s inherit %s set %s",
+				m.Name, m.Grantor, optionalBool(m.Admin), optionalBool(m.Inherit), optionalBool(m.Set),
+			))
+		}
+		slices.Sort(parents)
+		var config []string
+		for _, k := range slices.Sorted(maps.Keys(r.Config)) {
+			config = append(config, k+"="+r.Config[k])
+		}
+		for _, database := range slices.Sorted(maps.Keys(r.DatabaseConfig)) {
+			c := r.DatabaseConfig[database]
+			for _, k := range slices.Sorted(maps.Keys(c)) {
+				config = append(config, database+":"+k+"="+c[k])
+			}
+		}
+		var labels []string
+		for _, provider := range slices.Sorted(maps.Keys(r.SecurityLabels)) {
+			labels = append(labels, provider+"="+r.SecurityLabels[provider])
+		}
+		f.lines = append(f.lines, fmt.Sprintf(
+			"role %q options %q comment %q id %q parents %q config %q valid until %q security labels %q",
+			r.Name, r.Options.String(), r.Comment, r.ID, parents, config, role.ValidUntilString(r.ValidUntil), labels,
+		))
+	}
+}
+
+func (f *Fingerprint) AddGrants(database string, grants []privileges.Grant) {
+	for _, g := range grants {
+		f.lines = append(f.lines, fmt.Sprintf("grant in %q: %s", database, g))
+	}
+}
+
+// optionalBool formats membership option, nil when not inspected.
+func optionalBool(b *bool) string {
+	if b == nil {
+		return "nil"
+	}
+	return fmt.Sprint(*b)
+}
+
+// Sum returns the hexadecimal SHA-256 of inspected state.
+func (f Fingerprint) Sum() string {
+	lines := slices.Clone(f.lines)
+	slices.Sort(lines)
+	h := sha256.Sum256([]byte(strings.Join(lines, "\n")))
+	return hex.EncodeToString(h[:])
+}
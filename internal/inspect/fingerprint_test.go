@@ -0,0 +1,48 @@
+package inspect_test
+
+import (
+	"github.com/dalibo/ldap2pg/v6/internal/inspect"
+	"github.com/dalibo/ldap2pg/v6/internal/privileges"
+	"github.com/dalibo/ldap2pg/v6/internal/role"
+)
+
+func (suite *Suite) TestFingerprintOrder() {
+	r := suite.Require()
+
+	alice := role.Role{Name: "alice", Parents: []role.Membership{{Name: "a"}, {Name: "b"}}}
+	bob := role.Role{Name: "bob"}
+	grants := []privileges.Grant{
+		{ACL: "DATABASE", Type: "CONNECT", Database: "db", Grantee: "alice"},
+		{ACL: "DATABASE", Type: "CONNECT", Database: "db", Grantee: "bob"},
+	}
+
+	var f0, f1 inspect.Fingerprint
+	f0.AddRoles(role.Map{"alice": alice, "bob": bob})
+	f0.AddGrants("db", grants)
+
+	alice.Parents = []role.Membership{{Name: "b"}, {Name: "a"}}
+	f1.AddGrants("db", []privileges.Grant{grants[1], grants[0]})
+	f1.AddRoles(role.Map{"bob": bob, "alice": alice})
+	r.Equal(f0.Sum(), f1.Sum())
+
+	f1.AddGrants("db", []privileges.Grant{{ACL: "DATABASE", Type: "TEMPORARY", Database: "db", Grantee: "bob"}})
+	r.NotEqual(f0.Sum(), f1.Sum())
+}
+
+func (suite *Suite) TestFingerprintMembershipOptions() {
+	r := suite.Require()
+
+	yes, no := true, false
+	alice := role.Role{Name: "alice", Parents: []role.Membership{{Name: "a", Grantor: "postgres", Admin: &no, Inherit: &yes, Set: &yes}}}
+
+	var f0, f1, f2 inspect.Fingerprint
+	f0.AddRoles(role.Map{"alice": alice})
+
+	alice.Parents = []role.Membership{{Name: "a", Grantor: "postgres", Admin: &no, Inherit: &no, Set: &yes}}
+	f1.AddRoles(role.Map{"alice": alice})
+	r.NotEqual(f0.Sum(), f1.Sum())
+
+	alice.Parents = []role.Membership{{Name: "a", Grantor: "postgres", Admin: &yes, Inherit: &no, Set: &yes}}
+	f2.AddRoles(role.Map{"alice": alice})
+	r.NotEqual(f1.Sum(), f2.Sum())
+}
@@ -0,0 +1,60 @@
+package inspect
+
+import (
+	"context"
+	"log/slog"
+
+	_ "embed"
+
+	"github.com/dalibo/ldap2pg/v6/internal/postgres"
+	"github.com/jackc/pgx/v5"
+)
+
+//go:embed sql/owned.sql
+var ownedQuery string
+
+// OwnedObject is an object owned by a role, from pg_shdepend.
+type OwnedObject struct {
+	Role     string
+	Database string
+	Object   string
+}
+
+func (o OwnedObject) String() string {
+	if o.Database == "" {
+		return o.Object
+	}
+	return o.Object + " in " + o.Database
+}
+
+// InspectOwnedObjects lists objects owned by roles in all databases, indexed
+// by role name.
+func InspectOwnedObjects(ctx context.Context, roles []string) (owned map[string][]OwnedObject, err error) {
+	owned = make(map[string][]OwnedObject)
+	if len(roles) == 0 {
+		return
+	}
+	pgconn, err := postgres.GetConn(ctx, "")
+	if err != nil {
+		return
+	}
+
+	slog.Debug("Inspecting objects owned by dropped roles.", "roles", roles)
+	slog.Debug("Executing SQL query:\n" + ownedQuery)
+	var objects []OwnedObject
+	Watch.TimeIt(func() {
+		var rows pgx.Rows
+		rows, err = pgconn.Query(ctx, ownedQuery, roles)
+		if err != nil {
+			return
+		}
+		objects, err = pgx.CollectRows(rows, pgx.RowToStructByPos[OwnedObject])
+	})
+	if err != nil {
+		return
+	}
+	for _, o := range objects {
+		owned[o.Role] = append(owned[o.Role], o)
+	}
+	return
+}
@@ -0,0 +1,26 @@
+SELECT nspname, kind, name, arguments
+FROM (
+	SELECT
+		relnamespace AS nsp,
+		CASE
+			WHEN relkind IN ('v', 'm') THEN 'VIEW'
+			WHEN relkind = 'S' THEN 'SEQUENCE'
+			ELSE 'TABLE'
+		END AS kind,
+		relname AS name,
+		'' AS arguments
+	FROM pg_catalog.pg_class
+	WHERE relkind IN ('r', 'p', 'f', 'v', 'm', 'S')
+	UNION ALL
+	SELECT
+		pronamespace AS nsp,
+		'FUNCTION' AS kind,
+		proname AS name,
+		pg_catalog.oidvectortypes(proargtypes) AS arguments
+	FROM pg_catalog.pg_proc
+	-- Exclude procedures.
+	WHERE pg_catalog.pg_get_function_result(oid) IS NOT NULL
+) AS objects
+JOIN pg_catalog.pg_namespace AS nsp ON nsp.oid = objects.nsp
+WHERE nspname NOT LIKE 'pg\_%temp\_%' AND nspname <> 'pg_toast'
+ORDER BY 1, 2, 3, 4;
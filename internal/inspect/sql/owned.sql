@@ -0,0 +1,17 @@
+-- Objects owned by roles, in all databases.
+--
+-- Objects of other databases can't be described from current database.
+SELECT rol.rolname,
+       COALESCE(db.datname, '') AS database,
+       CASE
+         WHEN dep.dbid = 0 OR db.datname = current_database()
+         THEN pg_catalog.pg_describe_object(dep.classid, dep.objid, dep.objsubid)
+         ELSE dep.classid::regclass::text || ' ' || dep.objid
+       END AS object
+  FROM pg_catalog.pg_shdepend AS dep
+  JOIN pg_catalog.pg_roles AS rol ON rol.oid = dep.refobjid
+  LEFT OUTER JOIN pg_catalog.pg_database AS db ON db.oid = dep.dbid
+ WHERE dep.refclassid = 'pg_catalog.pg_authid'::regclass
+   AND dep.deptype = 'o'
+   AND rol.rolname = ANY($1)
+ ORDER BY 1, 2, 3
@@ -0,0 +1,4 @@
+-- Requires superuser. pg_roles masks passwords.
+SELECT rolname, COALESCE(rolpassword, '') AS rolpassword
+  FROM pg_catalog.pg_authid
+ ORDER BY 1
@@ -4,7 +4,11 @@ WITH me AS (
 ), memberships AS (
   SELECT ms.member AS member,
          p.rolname AS "name",
-         g.rolname AS "grantor"
+         g.rolname AS "grantor",
+         -- inherit_option and set_option are null before Postgres 16.
+         (to_jsonb(ms.*) ->> 'admin_option')::boolean AS "admin",
+         (to_jsonb(ms.*) ->> 'inherit_option')::boolean AS "inherit",
+         (to_jsonb(ms.*) ->> 'set_option')::boolean AS "set"
     FROM pg_auth_members AS ms
     JOIN pg_roles AS p ON p.oid = ms.roleid
     JOIN pg_roles AS g ON g.oid = ms.grantor
@@ -16,10 +20,20 @@ SELECT rol.rolname,
        -- Postgres 16 allows: json_arrayagg(memberships.* ORDER BY 2 ABSENT ON NULL)::jsonb AS parents,
        -- may return {NULL}, array_remove can't compare json object.
        array_agg(to_json(memberships.*)) AS parents,
-       rol.rolconfig AS config
+       rol.rolconfig AS config,
+       NULLIF(rol.rolvaliduntil, 'infinity') AS valid_until,
+       -- Config in specific database, indexed by database name.
+       (SELECT jsonb_object_agg(db.datname, setting.setconfig)
+          FROM pg_catalog.pg_db_role_setting AS setting
+          JOIN pg_catalog.pg_database AS db ON db.oid = setting.setdatabase
+         WHERE setting.setrole = rol.oid) AS database_config,
+       (SELECT jsonb_object_agg(seclabel.provider, seclabel.label)
+          FROM pg_catalog.pg_shseclabel AS seclabel
+         WHERE seclabel.objoid = rol.oid
+           AND seclabel.classoid = 'pg_catalog.pg_authid'::regclass) AS security_labels
   FROM me
        CROSS JOIN pg_catalog.pg_roles AS rol
        LEFT OUTER JOIN memberships ON memberships.member = rol.oid
  WHERE NOT (rol.rolsuper AND NOT me.rolsuper)
- GROUP BY 1, 2, 3, 5
+ GROUP BY 1, 2, 3, 5, 6, rol.oid
  ORDER BY 1
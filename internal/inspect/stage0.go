@@ -29,6 +29,12 @@ func Stage0(ctx context.Context, pc Config) (instance Instance, err error) {
 	slog.Debug("Stage 0: role blacklist.")
 	instance = Instance{}
 
+	// Lock before inspecting to prevent concurrent synchronization.
+	err = postgres.Lock(ctx, pc.LockKey, pc.LockTimeout)
+	if err != nil {
+		return
+	}
+
 	err = instance.InspectSession(ctx, pc.FallbackOwner)
 	if err != nil {
 		return instance, fmt.Errorf("session: %w", err)
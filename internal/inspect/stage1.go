@@ -19,6 +19,8 @@ var (
 	databasesQuery string
 	//go:embed sql/role-columns.sql
 	roleColumnsQuery string
+	//go:embed sql/passwords.sql
+	passwordsQuery string
 	//go:embed sql/roles.sql
 	rolesQuery string
 	//go:embed sql/session.sql
@@ -111,6 +113,15 @@ func (instance *Instance) InspectRoles(ctx context.Context, pgconn *pgx.Conn, ma
 		return fmt.Errorf("all: %w", err)
 	}
 
+	if instance.Me.Options.Super {
+		err := instance.InspectPasswords(ctx, pgconn)
+		if err != nil {
+			return fmt.Errorf("passwords: %w", err)
+		}
+	} else {
+		slog.Debug("Not superuser. Skipping passwords inspection.")
+	}
+
 	if nil == managedRolesQ {
 		slog.Debug("Managing all roles found.")
 		instance.ManagedRoles = instance.AllRoles
@@ -136,3 +147,24 @@ func (instance *Instance) InspectRoles(ctx context.Context, pgconn *pgx.Conn, ma
 
 	return nil
 }
+
+// InspectPasswords reads password hashes of all roles from pg_authid.
+func (instance *Instance) InspectPasswords(ctx context.Context, pgconn *pgx.Conn) error {
+	slog.Debug("Inspecting roles passwords.")
+	pq := &SQLQuery[rolePassword]{SQL: passwordsQuery, RowTo: pgx.RowToStructByPos[rolePassword]}
+	for pq.Query(ctx, pgconn); pq.Next(); {
+		row := pq.Row()
+		r, ok := instance.AllRoles[row.Name]
+		if !ok {
+			continue
+		}
+		r.Password = role.Password{Hash: row.Hash, Inspected: true}
+		instance.AllRoles[row.Name] = r
+	}
+	return pq.Err()
+}
+
+type rolePassword struct {
+	Name string
+	Hash string
+}
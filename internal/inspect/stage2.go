@@ -13,6 +13,9 @@ import (
 //go:embed sql/schemas.sql
 var schemasQuery string
 
+//go:embed sql/objects.sql
+var objectsQuery string
+
 func (instance *Instance) InspectStage2(ctx context.Context, dbname string, query Querier[postgres.Schema]) error {
 	err := instance.InspectSchemas(ctx, dbname, query)
 	if err != nil {
@@ -57,3 +60,34 @@ func (instance *Instance) InspectSchemas(ctx context.Context, dbname string, man
 
 	return nil
 }
+
+// InspectObjects lists relations and functions of managed schemas.
+//
+// Required to expand grants on individual objects.
+func (instance *Instance) InspectObjects(ctx context.Context, dbname string) error {
+	conn, err := postgres.GetConn(ctx, dbname)
+	if err != nil {
+		return err
+	}
+
+	database := postgres.Databases[dbname]
+	slog.Debug("Inspecting objects.", "database", dbname)
+	oq := &SQLQuery[postgres.Object]{SQL: objectsQuery, RowTo: postgres.RowToObject}
+	for oq.Query(ctx, conn); oq.Next(); {
+		o := oq.Row()
+		s, ok := database.Schemas[o.Schema]
+		if !ok {
+			continue
+		}
+		s.Objects = append(s.Objects, o)
+		database.Schemas[o.Schema] = s
+	}
+	err = oq.Err()
+	if err != nil {
+		return fmt.Errorf("objects: %w", err)
+	}
+
+	postgres.Databases[dbname] = database
+
+	return nil
+}
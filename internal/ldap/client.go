@@ -27,6 +27,42 @@ type Client struct {
 
 var Watch perf.StopWatch
 
+// KeepAlive keeps connection open after Release for next Open.
+var KeepAlive bool
+
+var kept *Client
+
+// Open returns a connected client, reusing kept alive connection if any.
+func Open() (Client, error) {
+	if kept != nil {
+		if !kept.Conn.IsClosing() {
+			slog.Debug("Reusing LDAP connection.", "uri", kept.URI)
+			return *kept, nil
+		}
+		kept = nil
+	}
+	return Connect()
+}
+
+// Release closes client connection unless KeepAlive is set.
+func Release(client Client) {
+	if KeepAlive {
+		kept = &client
+		return
+	}
+	_ = client.Conn.Close()
+}
+
+// Disconnect closes kept alive connection.
+func Disconnect() {
+	if kept == nil {
+		return
+	}
+	slog.Debug("Closing LDAP connection.", "uri", kept.URI)
+	_ = kept.Conn.Close()
+	kept = nil
+}
+
 func Connect() (client Client, err error) {
 	uri := k.String("URI")
 	uris := strings.Split(uri, " ")
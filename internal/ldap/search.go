@@ -1,16 +1,36 @@
 package ldap
 
 import (
+	"errors"
+	"fmt"
 	"maps"
 	"slices"
 )
 
+// ErrEntryCount reports a search returning too few or too many entries.
+var ErrEntryCount = errors.New("unexpected entry count")
+
 type Search struct {
 	Base        string
 	Scope       Scope
 	Filter      string
 	Attributes  []string
 	Subsearches map[string]Subsearch `mapstructure:"joins"`
+	MinEntries  int                  `mapstructure:"min_entries"`
+	MaxEntries  int                  `mapstructure:"max_entries"`
+}
+
+// CheckCount checks the number of entries returned by the search.
+//
+// Zero MinEntries or MaxEntries means no bound.
+func (s Search) CheckCount(count int) error {
+	if count < s.MinEntries {
+		return fmt.Errorf("%w: %d entries, expected at least %d", ErrEntryCount, count, s.MinEntries)
+	}
+	if s.MaxEntries > 0 && count > s.MaxEntries {
+		return fmt.Errorf("%w: %d entries, expected at most %d", ErrEntryCount, count, s.MaxEntries)
+	}
+	return nil
 }
 
 func (s Search) SubsearchAttribute() string {
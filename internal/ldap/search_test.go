@@ -0,0 +1,17 @@
+package ldap_test
+
+import "github.com/dalibo/ldap2pg/v6/internal/ldap"
+
+func (suite *Suite) TestSearchCheckCount() {
+	r := suite.Require()
+
+	s := ldap.Search{}
+	r.Nil(s.CheckCount(0))
+
+	s = ldap.Search{MinEntries: 2, MaxEntries: 3}
+	r.ErrorIs(s.CheckCount(1), ldap.ErrEntryCount)
+	r.ErrorContains(s.CheckCount(1), "1 entries, expected at least 2")
+	r.Nil(s.CheckCount(2))
+	r.Nil(s.CheckCount(3))
+	r.ErrorContains(s.CheckCount(4), "4 entries, expected at most 3")
+}
@@ -0,0 +1,74 @@
+package ldap
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Number of 100ns intervals between 1601-01-01 and 1970-01-01.
+const filetimeEpochOffset = 116444736000000000
+
+var generalizedTimeLayouts = []string{
+	"20060102150405Z0700",
+	"20060102150405.999999999Z0700",
+	"200601021504Z0700",
+	"2006010215Z0700",
+}
+
+// ParseTime parses an account expiration time from LDAP attribute value.
+//
+// Accepts Active Directory FILETIME like accountExpires, days since epoch like
+// shadowExpire and generalizedTime like pwdEndTime. RFC3339 is accepted too.
+// Returns zero time for values meaning never: 0, -1, max int64 FILETIME and
+// infinity. Result is truncated to the second.
+func ParseTime(s string) (t time.Time, err error) {
+	s = strings.TrimSpace(s)
+	switch strings.ToLower(s) {
+	case "", "0", "-1", "infinity", "never":
+		return
+	}
+
+	if n, err := strconv.ParseInt(s, 10, 64); err == nil {
+		return parseIntTime(s, n)
+	}
+
+	if t, err = time.Parse(time.RFC3339, s); err == nil {
+		return t.UTC().Truncate(time.Second), nil
+	}
+	for _, layout := range generalizedTimeLayouts {
+		t, err = time.Parse(layout, strings.Replace(s, ",", ".", 1))
+		if err == nil {
+			return t.UTC().Truncate(time.Second), nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("bad time: %s", s)
+}
+
+func parseIntTime(s string, n int64) (time.Time, error) {
+	switch {
+	case n == math.MaxInt64:
+		// accountExpires never.
+		return time.Time{}, nil
+	case n < 0:
+		return time.Time{}, fmt.Errorf("bad time: %s", s)
+	case len(s) <= 9:
+		// shadowExpire, days since epoch.
+		return time.Unix(n*24*3600, 0).UTC(), nil
+	case len(s) >= 15:
+		// FILETIME, 100ns intervals since 1601-01-01.
+		n -= filetimeEpochOffset
+		return time.Unix(n/10_000_000, 0).UTC(), nil
+	default:
+		// generalizedTime without zone, assuming UTC.
+		for _, layout := range []string{"20060102150405", "200601021504", "2006010215"} {
+			t, err := time.Parse(layout, s)
+			if err == nil {
+				return t, nil
+			}
+		}
+		return time.Time{}, fmt.Errorf("bad time: %s", s)
+	}
+}
@@ -0,0 +1,35 @@
+package ldap_test
+
+import (
+	"time"
+
+	"github.com/dalibo/ldap2pg/v6/internal/ldap"
+)
+
+func (suite *Suite) TestParseTime() {
+	r := suite.Require()
+
+	want := time.Date(2025, 12, 31, 0, 0, 0, 0, time.UTC)
+	for _, s := range []string{
+		"134116128000000000", // accountExpires
+		"20453",              // shadowExpire
+		"20251231000000Z",    // pwdEndTime
+		"20251231000000.0Z",
+		"20251231010000+0100",
+		"20251231000000",
+		"2025-12-31T00:00:00Z",
+	} {
+		t, err := ldap.ParseTime(s)
+		r.Nil(err, s)
+		r.True(want.Equal(t), "%s: %s", s, t)
+	}
+
+	for _, s := range []string{"", "0", "-1", "9223372036854775807", "infinity"} {
+		t, err := ldap.ParseTime(s)
+		r.Nil(err, s)
+		r.True(t.IsZero(), s)
+	}
+
+	_, err := ldap.ParseTime("tomorrow")
+	r.ErrorContains(err, "bad time")
+}
@@ -2,9 +2,11 @@ package postgres
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"log/slog"
 	"slices"
+	"sync/atomic"
 
 	"github.com/dalibo/ldap2pg/v6/internal"
 	"github.com/dalibo/ldap2pg/v6/internal/errorlist"
@@ -15,8 +17,20 @@ import (
 var (
 	Watch     perf.StopWatch
 	formatter = FmtQueryRewriter{}
+
+	interrupted atomic.Bool
 )
 
+// ErrInterrupted reports Apply stopped before executing all queries.
+var ErrInterrupted = errors.New("interrupted")
+
+// Interrupt tells Apply to stop before next query.
+//
+// Safe to call from a signal handler goroutine.
+func Interrupt() {
+	interrupted.Store(true)
+}
+
 func Apply(ctx context.Context, diff <-chan SyncQuery, really bool) (count int, err error) {
 	prefix := ""
 	if !really {
@@ -24,40 +38,98 @@ func Apply(ctx context.Context, diff <-chan SyncQuery, really bool) (count int,
 	}
 
 	errs := errorlist.New("synchronisation errors")
+	// Database of rolled back batch, in database transaction mode.
+	skipped := ""
 	for query := range diff {
+		if interrupted.Load() {
+			slog.Warn("Interrupted. Skipping remaining queries.")
+			if TransactionMode != "" {
+				errs.Append(rollback(ctx, "", ErrInterrupted))
+			} else {
+				errs.Append(ErrInterrupted)
+			}
+			break
+		}
 		if !slices.ContainsFunc(query.LogArgs, func(i any) bool {
 			return i == "database"
 		}) {
 			query.LogArgs = append(query.LogArgs, "database", query.Database)
 		}
+		if skipped != "" && query.Database == skipped {
+			slog.Debug("Skipping query of rolled back batch.", query.LogArgs...)
+			continue
+		}
 		slog.Log(ctx, internal.LevelChange, prefix+query.Description, query.LogArgs...)
 		count++
 		pgConn, err := GetConn(ctx, query.Database)
 		if err != nil {
-			return count, fmt.Errorf("PostgreSQL error: %w", err)
+			return count, abort(ctx, fmt.Errorf("PostgreSQL error: %w", err))
 		}
 
 		// Rewrite query to log a pasteable query even when in Dry mode.
 		sql, _, _ := formatter.RewriteQuery(ctx, pgConn, query.Query, query.QueryArgs)
 		slog.Debug(prefix + "Execute SQL query:\n" + sql)
+		CurrentPlan.record(query, sql)
 
 		if !really {
 			continue
 		}
 
+		database := pgConn.Config().Database
+		if TransactionMode != "" {
+			err = begin(ctx, pgConn, database)
+			if err != nil {
+				return count, abort(ctx, fmt.Errorf("PostgreSQL error: %w", err))
+			}
+		}
+
+		execSQL := sql
+		if query.HasSecret() {
+			execSQL, _, err = FmtQueryRewriter{RevealSecrets: true}.RewriteQuery(ctx, pgConn, query.Query, query.QueryArgs)
+			if err != nil {
+				return count, abort(ctx, fmt.Errorf("PostgreSQL error: %w", err))
+			}
+		}
+
 		var tag pgconn.CommandTag
 		duration := Watch.TimeIt(func() {
-			_, err = pgConn.Exec(ctx, sql)
+			tag, err = pgConn.Exec(ctx, execSQL)
 		})
+		record := auditRecord{
+			database:    database,
+			description: query.Description,
+			args:        LogArgsMap(query.LogArgs),
+			sql:         sql,
+			err:         err,
+		}
+		if t, ok := transactions[database]; ok {
+			// Audit on commit or rollback.
+			t.audit = append(t.audit, record)
+		} else if aerr := CurrentAudit.record(ctx, record); aerr != nil {
+			return count, abort(ctx, fmt.Errorf("audit: %w", aerr))
+		}
 		if err != nil {
 			slog.Error("Synchronisation error.", "err", err)
+			if TransactionMode != "" {
+				if !errs.Append(rollback(ctx, database, err)) || TransactionMode == "all" {
+					break
+				}
+				skipped = query.Database
+				continue
+			}
 			if !errs.Append(err) {
 				break
 			}
 		} else {
 			slog.Debug("Query terminated.", "duration", duration, "rows", tag.RowsAffected())
+			if t, ok := transactions[database]; ok {
+				t.queries++
+			}
 		}
 	}
+	if TransactionMode == "database" {
+		errs.Append(Commit(ctx))
+	}
 	if errs.Len() > 0 {
 		return count, errs
 	}
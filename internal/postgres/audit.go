@@ -0,0 +1,142 @@
+package postgres
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"slices"
+	"strings"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// CurrentAudit records executed queries in a table. nil disables audit.
+var CurrentAudit *Audit
+
+// Audit configures the audit table of executed queries.
+type Audit struct {
+	// Table is the optionally schema-qualified name of the audit table.
+	Table string
+	// Database hosting the audit table. Defaults to connection database.
+	Database string
+
+	runID string
+	conn  *pgx.Conn
+}
+
+const auditTableSQL = `CREATE TABLE IF NOT EXISTS %s (
+	run_id uuid NOT NULL,
+	ts timestamp with time zone NOT NULL DEFAULT clock_timestamp(),
+	database name NOT NULL,
+	description text NOT NULL,
+	args jsonb NOT NULL,
+	sql text NOT NULL,
+	error text
+);`
+
+const auditInsertSQL = `INSERT INTO %s (run_id, database, description, args, sql, error)
+VALUES ($1, $2, $3, $4, $5, $6);`
+
+// Open connects to audit database and creates audit table if missing.
+//
+// Open generates a new run id shared by all records.
+func (a *Audit) Open(ctx context.Context) (err error) {
+	a.runID, err = newUUID()
+	if err != nil {
+		return
+	}
+	c := globalConf.Copy()
+	if a.Database != "" {
+		c.Database = a.Database
+	}
+	slog.Debug("Opening Postgres audit connection.", "database", c.Database)
+	a.conn, err = pgx.ConnectConfig(ctx, c)
+	if err != nil {
+		return
+	}
+	sql := fmt.Sprintf(auditTableSQL, a.identifier())
+	slog.Debug("Executing SQL query:\n" + sql)
+	_, err = a.conn.Exec(ctx, sql)
+	if err != nil {
+		return
+	}
+	slog.Debug("Auditing changes.", "table", a.Table, "database", c.Database, "run", a.runID)
+	return
+}
+
+// Close audit connection.
+func (a *Audit) Close(ctx context.Context) {
+	if a == nil || a.conn == nil {
+		return
+	}
+	slog.Debug("Closing Postgres audit connection.")
+	_ = a.conn.Close(ctx)
+	a.conn = nil
+}
+
+// auditRecord holds audit row of an executed query.
+type auditRecord struct {
+	database    string
+	description string
+	args        map[string]any
+	sql         string
+	err         error
+}
+
+// rolledBack marks successful records as rolled back by cause.
+func rolledBack(records []auditRecord, cause error) []auditRecord {
+	out := slices.Clone(records)
+	for i := range out {
+		if out[i].err == nil {
+			out[i].err = fmt.Errorf("rolled back: %w", cause)
+		}
+	}
+	return out
+}
+
+// record inserts audit rows for executed queries. Noop on nil audit.
+func (a *Audit) record(ctx context.Context, records ...auditRecord) error {
+	if a == nil || len(records) == 0 {
+		return nil
+	}
+	if a.conn == nil {
+		return fmt.Errorf("audit connection closed")
+	}
+	for _, r := range records {
+		jsonArgs, err := json.Marshal(r.args)
+		if err != nil {
+			return err
+		}
+		var errMsg *string
+		if r.err != nil {
+			msg := r.err.Error()
+			errMsg = &msg
+		}
+		_, err = a.conn.Exec(
+			ctx, fmt.Sprintf(auditInsertSQL, a.identifier()),
+			a.runID, r.database, r.description, string(jsonArgs), r.sql, errMsg,
+		)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (a *Audit) identifier() string {
+	return pgx.Identifier(strings.Split(a.Table, ".")).Sanitize()
+}
+
+// newUUID generates a random version 4 UUID.
+func newUUID() (string, error) {
+	b := make([]byte, 16)
+	_, err := rand.Read(b)
+	if err != nil {
+		return "", err
+	}
+	b[6] = b[6]&0x0f | 0x40
+	b[8] = b[8]&0x3f | 0x80
+	return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:]), nil
+}
@@ -0,0 +1,83 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeTx records end of transaction.
+type fakeTx struct {
+	pgx.Tx
+	ended string
+}
+
+func (t *fakeTx) Commit(context.Context) error {
+	t.ended = "commit"
+	return nil
+}
+
+func (t *fakeTx) Rollback(context.Context) error {
+	t.ended = "rollback"
+	return nil
+}
+
+func TestAuditRolledBack(t *testing.T) {
+	r := require.New(t)
+
+	cause := errors.New("role does not exist")
+	records := []auditRecord{
+		{database: "db", description: "Create role."},
+		{database: "db", description: "Grant privileges.", err: cause},
+	}
+	marked := rolledBack(records, cause)
+	r.EqualError(marked[0].err, "rolled back: role does not exist")
+	r.Equal(cause, marked[1].err)
+	// Don't alter pending records.
+	r.Nil(records[0].err)
+}
+
+func TestAbortRollsBackOnAuditError(t *testing.T) {
+	r := require.New(t)
+
+	defer func() {
+		CurrentAudit = nil
+		transactions = map[string]*transaction{}
+	}()
+	// Closed audit fails to record.
+	CurrentAudit = &Audit{Table: "audit"}
+	tx := &fakeTx{}
+	transactions["db"] = &transaction{tx: tx, queries: 1, audit: []auditRecord{{database: "db", description: "Create role."}}}
+
+	err := abort(context.Background(), errors.New("audit: connection lost"))
+	r.Equal("rollback", tx.ended)
+	r.Empty(transactions)
+	r.ErrorContains(err, "audit connection closed")
+	rerr, ok := errors.AsType[RollbackError](err)
+	r.True(ok)
+	r.Equal(1, rerr.Queries)
+
+	// Nothing to roll back.
+	cause := errors.New("connection refused")
+	r.Equal(cause, abort(context.Background(), cause))
+}
+
+func TestCommitAuditsAfterCommit(t *testing.T) {
+	r := require.New(t)
+
+	defer func() {
+		CurrentAudit = nil
+		transactions = map[string]*transaction{}
+	}()
+	tx := &fakeTx{}
+	transactions["db"] = &transaction{tx: tx, audit: []auditRecord{{database: "db", description: "Create role."}}}
+	CurrentAudit = &Audit{Table: "audit"}
+
+	// Commit before auditing, even if audit fails.
+	err := Commit(context.Background())
+	r.Equal("commit", tx.ended)
+	r.ErrorContains(err, "audit: audit connection closed")
+}
@@ -44,13 +44,27 @@ func GetConn(ctx context.Context, database string) (*pgx.Conn, error) {
 		database = globalConf.Database
 	}
 
+	if nil != globalConn && globalConn.IsClosed() {
+		slog.Debug("Postgres global connection lost.")
+		globalConn = nil
+	}
+
 	if nil != globalConn {
 		c := globalConn.Config()
 		if database != c.Database {
-			CloseConn(ctx)
+			if _, ok := transactions[c.Database]; ok {
+				// Keep connection open until end of transaction.
+				globalConn = nil
+			} else {
+				closeGlobalConn(ctx)
+			}
 		}
 	}
 
+	if t, ok := transactions[database]; ok && nil == globalConn {
+		globalConn = t.conn
+	}
+
 	if nil == globalConn {
 		var err error
 		slog.Debug("Opening Postgres global connection.", "database", database)
@@ -65,7 +79,25 @@ func GetConn(ctx context.Context, database string) (*pgx.Conn, error) {
 	return globalConn, nil
 }
 
+// CloseConn closes all connections, rolling back open transactions.
 func CloseConn(ctx context.Context) {
+	for database, t := range transactions {
+		if t.conn == globalConn {
+			continue
+		}
+		slog.Debug("Closing Postgres connection with open transaction.", "database", database)
+		_ = t.conn.Close(ctx)
+		delete(transactions, database)
+	}
+	closeGlobalConn(ctx)
+	// Closing session releases advisory lock.
+	if nil != lockConn {
+		_ = lockConn.Close(ctx)
+		lockConn = nil
+	}
+}
+
+func closeGlobalConn(ctx context.Context) {
 	if nil == globalConn {
 		return
 	}
@@ -73,5 +105,6 @@ func CloseConn(ctx context.Context) {
 	slog.Debug("Closing Postgres global connection.", "database", c.Database)
 
 	_ = globalConn.Close(ctx)
+	delete(transactions, c.Database)
 	globalConn = nil
 }
@@ -0,0 +1,76 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+// ErrLocked reports that another session holds the advisory lock.
+var ErrLocked = errors.New("another ldap2pg is running")
+
+var (
+	lockConn *pgx.Conn
+	lockKey  int64
+)
+
+// Lock takes a cluster-wide advisory lock on a dedicated connection.
+//
+// Lock waits up to timeout for the lock. A zero timeout fails immediately if
+// another session holds the lock. The lock is held until Unlock or CloseConn.
+func Lock(ctx context.Context, key int64, timeout time.Duration) error {
+	Unlock(ctx)
+	c := globalConf.Copy()
+	slog.Debug("Opening Postgres lock connection.", "database", c.Database)
+	conn, err := pgx.ConnectConfig(ctx, c)
+	if err != nil {
+		return err
+	}
+
+	slog.Debug("Acquiring advisory lock.", "key", key, "timeout", timeout)
+	locked := false
+	if timeout == 0 {
+		err = conn.QueryRow(ctx, "SELECT pg_try_advisory_lock($1);", key).Scan(&locked)
+	} else {
+		_, err = conn.Exec(ctx, fmt.Sprintf("SET lock_timeout TO %d;", timeout.Milliseconds()))
+		if err == nil {
+			_, err = conn.Exec(ctx, "SELECT pg_advisory_lock($1);", key)
+			locked = err == nil
+		}
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == "55P03" { // lock_not_available
+			err = nil
+		}
+	}
+	if err != nil {
+		_ = conn.Close(ctx)
+		return fmt.Errorf("lock: %w", err)
+	}
+	if !locked {
+		_ = conn.Close(ctx)
+		return fmt.Errorf("%w: advisory lock %d not available", ErrLocked, key)
+	}
+	slog.Debug("Advisory lock acquired.", "key", key)
+	lockConn = conn
+	lockKey = key
+	return nil
+}
+
+// Unlock releases the advisory lock and closes its connection.
+func Unlock(ctx context.Context) {
+	if nil == lockConn {
+		return
+	}
+	slog.Debug("Releasing advisory lock.", "key", lockKey)
+	_, err := lockConn.Exec(ctx, "SELECT pg_advisory_unlock($1);", lockKey)
+	if err != nil {
+		slog.Warn("Failed to release advisory lock.", "key", lockKey, "err", err)
+	}
+	_ = lockConn.Close(ctx)
+	lockConn = nil
+}
@@ -47,6 +47,20 @@ type Schema struct {
 	Name     string
 	Owner    string
 	Creators []string
+	Objects  []Object
+}
+
+// Object references a relation or a function in a schema.
+type Object struct {
+	Schema    string
+	Kind      string // TABLE, VIEW, SEQUENCE or FUNCTION.
+	Name      string
+	Arguments string // Argument types of function. Empty otherwise.
+}
+
+func RowToObject(row pgx.CollectableRow) (o Object, err error) {
+	err = row.Scan(&o.Schema, &o.Kind, &o.Name, &o.Arguments)
+	return
 }
 
 func RowToSchema(row pgx.CollectableRow) (s Schema, err error) {
@@ -0,0 +1,222 @@
+package postgres
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"maps"
+	"reflect"
+	"regexp"
+	"slices"
+	"strings"
+)
+
+// CurrentPlan records queries passed to Apply. nil disables recording.
+var CurrentPlan *Plan
+
+// PlannedQuery is a SyncQuery with its final SQL, ready for serialization.
+type PlannedQuery struct {
+	Description string         `json:"description"`
+	Database    string         `json:"database"`
+	LogArgs     map[string]any `json:"args"`
+	SQL         string         `json:"sql"`
+	// Masked is true if SQL has masked secrets. Such query can't be applied.
+	Masked bool `json:"masked,omitempty"`
+}
+
+// Threshold records a safety threshold exceeded when planning.
+type Threshold struct {
+	Param string `json:"param"`
+	Count int    `json:"count"`
+	Max   int    `json:"max"`
+}
+
+// Plan holds queries grouped by synchronization phase.
+//
+// Fingerprint and ManagedRoles allow to check for changes in Postgres before
+// applying a saved plan. Thresholds lists thresholds to check again before
+// applying.
+type Plan struct {
+	Fingerprint       string         `json:"fingerprint,omitempty"`
+	ManagedRoles      []string       `json:"managed_roles,omitempty"`
+	Thresholds        []Threshold    `json:"thresholds,omitempty"`
+	PreSync           []PlannedQuery `json:"pre_sync,omitempty"`
+	Roles             []PlannedQuery `json:"roles"`
+	Privileges        []PlannedQuery `json:"privileges"`
+	DefaultPrivileges []PlannedQuery `json:"default_privileges"`
+	PostSync          []PlannedQuery `json:"post_sync,omitempty"`
+
+	phase *[]PlannedQuery
+}
+
+func NewPlan() *Plan {
+	return &Plan{
+		Roles:             []PlannedQuery{},
+		Privileges:        []PlannedQuery{},
+		DefaultPrivileges: []PlannedQuery{},
+	}
+}
+
+// Phase selects the phase of next recorded queries.
+//
+// name is one of pre sync, roles, privileges, default privileges or post
+// sync. Noop on nil plan.
+func (p *Plan) Phase(name string) {
+	if p == nil {
+		return
+	}
+	switch name {
+	case "pre sync":
+		p.phase = &p.PreSync
+	case "roles":
+		p.phase = &p.Roles
+	case "privileges":
+		p.phase = &p.Privileges
+	case "default privileges":
+		p.phase = &p.DefaultPrivileges
+	case "post sync":
+		p.phase = &p.PostSync
+	default:
+		panic(fmt.Sprintf("unknown phase %q", name))
+	}
+}
+
+// Exceed records an exceeded threshold. Noop on nil plan.
+func (p *Plan) Exceed(param string, count, max int) {
+	if p == nil {
+		return
+	}
+	p.Thresholds = append(p.Thresholds, Threshold{Param: param, Count: count, Max: max})
+}
+
+func (p *Plan) record(q SyncQuery, sql string) {
+	if p == nil {
+		return
+	}
+	if p.phase == nil {
+		p.Phase("roles")
+	}
+	*p.phase = append(*p.phase, PlannedQuery{
+		Description: q.Description,
+		Database:    q.Database,
+		LogArgs:     LogArgsMap(q.LogArgs),
+		SQL:         sql,
+		Masked:      q.HasSecret(),
+	})
+}
+
+// ReadPlan loads a plan saved as JSON.
+func ReadPlan(r io.Reader) (*Plan, error) {
+	p := NewPlan()
+	dec := json.NewDecoder(r)
+	dec.DisallowUnknownFields()
+	err := dec.Decode(p)
+	return p, err
+}
+
+// SyncQuery converts back a planned query for Apply.
+func (q PlannedQuery) SyncQuery() SyncQuery {
+	var args []any
+	for _, k := range slices.Sorted(maps.Keys(q.LogArgs)) {
+		args = append(args, k, q.LogArgs[k])
+	}
+	return SyncQuery{
+		Description: q.Description,
+		LogArgs:     args,
+		Database:    q.Database,
+		// SQL is final, protect it from formatting.
+		Query: strings.ReplaceAll(q.SQL, "%", "%%"),
+	}
+}
+
+// WriteJSON serializes plan as an indented JSON document.
+func (p *Plan) WriteJSON(w io.Writer) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(p)
+}
+
+// LogArgsMap converts slog key-value pairs to a map of JSON-friendly values.
+func LogArgsMap(args []any) map[string]any {
+	m := make(map[string]any)
+	for i := 0; i+1 < len(args); i += 2 {
+		key, ok := args[i].(string)
+		if !ok {
+			key = fmt.Sprint(args[i])
+		}
+		m[key] = jsonValue(args[i+1])
+	}
+	return m
+}
+
+func jsonValue(v any) any {
+	switch v := v.(type) {
+	case nil, string, bool, int, int32, int64, float64:
+		return v
+	case fmt.Stringer:
+		return v.String()
+	}
+	rv := reflect.ValueOf(v)
+	if rv.Kind() == reflect.Slice {
+		out := make([]any, rv.Len())
+		for i := range rv.Len() {
+			out[i] = jsonValue(rv.Index(i).Interface())
+		}
+		return out
+	}
+	return fmt.Sprint(v)
+}
+
+// WriteSQL serializes plan as a psql script.
+//
+// Emits \connect meta-command on each database switch so that psql -f can
+// replay the whole plan. Queries with masked secrets are commented out.
+func (p *Plan) WriteSQL(w io.Writer) (err error) {
+	b := strings.Builder{}
+	b.WriteString("-- Synchronization script generated by ldap2pg.\n")
+	b.WriteString("\\set ON_ERROR_STOP on\n")
+	database := ""
+	for _, q := range p.Queries() {
+		if q.Database != database {
+			database = q.Database
+			fmt.Fprintf(&b, "\n\\connect %s\n", psqlQuote(database))
+		}
+		fmt.Fprintf(&b, "\n-- %s\n", q.Description)
+		if q.Masked {
+			// Don't let psql set a masked password.
+			b.WriteString("-- Secret masked, query disabled.\n-- ")
+			b.WriteString(strings.ReplaceAll(q.SQL, "\n", "\n-- "))
+			b.WriteByte('\n')
+			continue
+		}
+		b.WriteString(q.SQL)
+		if !strings.HasSuffix(q.SQL, ";") {
+			b.WriteByte(';')
+		}
+		b.WriteByte('\n')
+	}
+	_, err = io.WriteString(w, b.String())
+	return
+}
+
+// Queries returns all queries of the plan, phase after phase.
+func (p *Plan) Queries() (out []PlannedQuery) {
+	out = append(out, p.PreSync...)
+	out = append(out, p.Roles...)
+	out = append(out, p.Privileges...)
+	out = append(out, p.DefaultPrivileges...)
+	out = append(out, p.PostSync...)
+	return
+}
+
+var psqlPlainRe = regexp.MustCompile(`^[a-z_][a-z0-9_]*$`)
+
+// psqlQuote quotes a meta-command argument for psql.
+func psqlQuote(s string) string {
+	if psqlPlainRe.MatchString(s) {
+		return s
+	}
+	s = strings.ReplaceAll(s, `\`, `\\`)
+	s = strings.ReplaceAll(s, `'`, `\'`)
+	return "'" + s + "'"
+}
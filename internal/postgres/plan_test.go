@@ -0,0 +1,168 @@
+package postgres_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+
+	"github.com/dalibo/ldap2pg/v6/internal/postgres"
+	"github.com/stretchr/testify/require"
+)
+
+type stringer struct{ s string }
+
+func (s stringer) String() string {
+	return s.s
+}
+
+func TestLogArgsMap(t *testing.T) {
+	r := require.New(t)
+
+	m := postgres.LogArgsMap([]any{
+		"role", "alice",
+		"parents", []stringer{{"readers"}, {"writers"}},
+		"grant", stringer{"CONNECT ON DATABASE db TO alice"},
+	})
+	r.Equal("alice", m["role"])
+	r.Equal([]any{"readers", "writers"}, m["parents"])
+	r.Equal("CONNECT ON DATABASE db TO alice", m["grant"])
+}
+
+func TestPlanJSON(t *testing.T) {
+	r := require.New(t)
+
+	p := postgres.NewPlan()
+	var b bytes.Buffer
+	r.Nil(p.WriteJSON(&b))
+
+	var doc map[string]any
+	r.Nil(json.Unmarshal(b.Bytes(), &doc))
+	r.Equal([]any{}, doc["roles"])
+	r.Equal([]any{}, doc["privileges"])
+	r.Equal([]any{}, doc["default_privileges"])
+}
+
+func TestPlanSQL(t *testing.T) {
+	r := require.New(t)
+
+	p := postgres.NewPlan()
+	p.Roles = append(p.Roles, postgres.PlannedQuery{
+		Description: "Create role.",
+		Database:    "postgres",
+		SQL:         `CREATE ROLE "alice" WITH LOGIN;`,
+	}, postgres.PlannedQuery{
+		Description: "Reassign objects and purge ACL.",
+		Database:    "App DB",
+		SQL:         `REASSIGN OWNED BY "bob" TO "app"; DROP OWNED BY "bob";`,
+	})
+	p.Privileges = append(p.Privileges, postgres.PlannedQuery{
+		Description: "Grant privileges.",
+		Database:    "App DB",
+		SQL:         `GRANT CONNECT ON DATABASE "App DB" TO "alice"`,
+	})
+
+	var b bytes.Buffer
+	r.Nil(p.WriteSQL(&b))
+	script := b.String()
+	r.Contains(script, "\\connect postgres\n\n-- Create role.\nCREATE ROLE")
+	r.Contains(script, "\\connect 'App DB'\n")
+	r.Equal(1, bytes.Count(b.Bytes(), []byte("\\connect 'App DB'")))
+	r.Contains(script, `TO "alice";`)
+}
+
+func TestPlanSyncHooks(t *testing.T) {
+	r := require.New(t)
+
+	p := postgres.NewPlan()
+	p.PostSync = append(p.PostSync, postgres.PlannedQuery{
+		Description: "Run hook.",
+		Database:    "postgres",
+		SQL:         `REFRESH MATERIALIZED VIEW acl;`,
+	})
+	p.PreSync = append(p.PreSync, postgres.PlannedQuery{
+		Description: "Run hook.",
+		Database:    "postgres",
+		SQL:         `SET lock_timeout TO '5s';`,
+	})
+	p.Roles = append(p.Roles, postgres.PlannedQuery{
+		Description: "Create role.",
+		Database:    "postgres",
+		SQL:         `CREATE ROLE "alice";`,
+	})
+
+	queries := p.Queries()
+	r.Len(queries, 3)
+	r.Contains(queries[0].SQL, "lock_timeout")
+	r.Contains(queries[2].SQL, "REFRESH")
+
+	var b bytes.Buffer
+	r.Nil(p.WriteJSON(&b))
+	p, err := postgres.ReadPlan(&b)
+	r.Nil(err)
+	r.Len(p.PreSync, 1)
+	r.Len(p.PostSync, 1)
+}
+
+func TestPlanRoundtrip(t *testing.T) {
+	r := require.New(t)
+
+	p := postgres.NewPlan()
+	p.Fingerprint = "abcd"
+	p.ManagedRoles = []string{"alice"}
+	p.Roles = append(p.Roles, postgres.PlannedQuery{
+		Description: "Revoke privileges.",
+		Database:    "db",
+		LogArgs:     map[string]any{"role": "alice"},
+		SQL:         `REVOKE USAGE ON SCHEMA "50%" FROM "alice";`,
+	})
+	var b bytes.Buffer
+	r.Nil(p.WriteJSON(&b))
+
+	p, err := postgres.ReadPlan(&b)
+	r.Nil(err)
+	r.Equal("abcd", p.Fingerprint)
+	r.Len(p.Roles, 1)
+	q := p.Roles[0].SyncQuery()
+	r.Equal("db", q.Database)
+	r.Equal([]any{"role", "alice"}, q.LogArgs)
+	r.Equal(`REVOKE USAGE ON SCHEMA "50%%" FROM "alice";`, q.Query)
+}
+
+func TestPlanThresholds(t *testing.T) {
+	r := require.New(t)
+
+	var nilPlan *postgres.Plan
+	nilPlan.Exceed("max_dropped_roles", 12, 10)
+
+	p := postgres.NewPlan()
+	p.Exceed("max_dropped_roles", 12, 10)
+	var b bytes.Buffer
+	r.Nil(p.WriteJSON(&b))
+
+	// apply checks thresholds of saved plan again.
+	p, err := postgres.ReadPlan(&b)
+	r.Nil(err)
+	r.Equal([]postgres.Threshold{{Param: "max_dropped_roles", Count: 12, Max: 10}}, p.Thresholds)
+}
+
+func TestPlanMaskedSecret(t *testing.T) {
+	r := require.New(t)
+
+	q := postgres.SyncQuery{
+		Query:     `ALTER ROLE %s WITH PASSWORD %s;`,
+		QueryArgs: []any{"alice", postgres.Secret("SCRAM-SHA-256$hash")},
+	}
+	r.True(q.HasSecret())
+	r.Equal("********", postgres.Secret("SCRAM-SHA-256$hash").String())
+
+	p := postgres.NewPlan()
+	p.Roles = append(p.Roles, postgres.PlannedQuery{
+		Description: "Set role password.",
+		Database:    "postgres",
+		SQL:         `ALTER ROLE "alice" WITH PASSWORD '********';`,
+		Masked:      true,
+	})
+	var b bytes.Buffer
+	r.Nil(p.WriteSQL(&b))
+	r.Contains(b.String(), "\n-- ALTER ROLE \"alice\" WITH PASSWORD")
+}
@@ -4,6 +4,8 @@ package postgres
 import (
 	"context"
 	"fmt"
+	"log/slog"
+	"slices"
 	"strings"
 
 	"github.com/jackc/pgx/v5"
@@ -18,6 +20,9 @@ type SyncQuery struct {
 	Database    string
 	Query       string
 	QueryArgs   []any
+	// After tells GroupByDatabase to yield query after queries of all
+	// databases.
+	After bool
 }
 
 func (q SyncQuery) IsZero() bool {
@@ -28,12 +33,45 @@ func (q SyncQuery) String() string {
 	return q.Description
 }
 
-type FmtQueryRewriter struct{}
+// HasSecret returns whether query has a Secret argument.
+func (q SyncQuery) HasSecret() bool {
+	return slices.ContainsFunc(q.QueryArgs, func(arg any) bool {
+		_, ok := arg.(Secret)
+		return ok
+	})
+}
+
+// Secret is a query argument masked in logs, plans and audit.
+type Secret string
+
+const secretMask = "********"
+
+func (Secret) String() string {
+	return secretMask
+}
+
+func (Secret) LogValue() slog.Value {
+	return slog.StringValue(secretMask)
+}
+
+// FmtQueryRewriter formats query arguments as SQL literals.
+//
+// Secret arguments are masked unless RevealSecrets is true.
+type FmtQueryRewriter struct {
+	RevealSecrets bool
+}
 
 func (q FmtQueryRewriter) RewriteQuery(_ context.Context, conn *pgx.Conn, sql string, args []any) (newSQL string, newArgs []any, err error) {
 	sql = strings.TrimSpace(dedent.Dedent(sql))
 	var fmtArgs []any
 	for _, arg := range args {
+		if secret, ok := arg.(Secret); ok {
+			if q.RevealSecrets {
+				arg = string(secret)
+			} else {
+				arg = secretMask
+			}
+		}
 		arg, err = formatArg(conn, arg)
 		if err != nil {
 			return
@@ -73,6 +111,10 @@ func formatArg(conn *pgx.Conn, arg any) (newArg any, err error) {
 	return
 }
 
+// GroupByDatabase reorders queries to run them database by database.
+//
+// Default database comes last. Queries flagged with After come after all
+// databases, in order.
 func GroupByDatabase(defaultDatabase string, in <-chan SyncQuery) chan SyncQuery {
 	ch := make(chan SyncQuery)
 	go func() {
@@ -92,11 +134,16 @@ func GroupByDatabase(defaultDatabase string, in <-chan SyncQuery) chan SyncQuery
 
 		for _, name := range databases {
 			for _, q := range queries {
-				if q.Database == name {
+				if q.Database == name && !q.After {
 					ch <- q
 				}
 			}
 		}
+		for _, q := range queries {
+			if q.After {
+				ch <- q
+			}
+		}
 	}()
 	return ch
 }
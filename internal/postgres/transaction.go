@@ -0,0 +1,167 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"maps"
+	"slices"
+	"strings"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// TransactionMode selects how Apply wraps queries in transactions.
+//
+// Empty string means autocommit. database commits each database batch and
+// rolls it back on first error. all keeps a transaction open until Commit and
+// rolls back everything on first error. See CheckTransactionMode.
+var TransactionMode string
+
+// CheckTransactionMode refuses all mode when synchronization spans several
+// databases.
+//
+// Postgres transactions can't span databases. Other databases don't see a
+// role created in the uncommitted transaction of default database, and
+// DROP ROLE waits for uncommitted DROP OWNED in other databases.
+func CheckTransactionMode(defaultDatabase string) error {
+	if TransactionMode != "all" {
+		return nil
+	}
+	for _, name := range slices.Sorted(maps.Keys(Databases)) {
+		if name != defaultDatabase {
+			return fmt.Errorf("transaction all: can't synchronize database %s besides %s, use transaction database", name, defaultDatabase)
+		}
+	}
+	return nil
+}
+
+// transactions holds open transactions by database.
+//
+// A connection with an open transaction is kept open when GetConn switches to
+// another database.
+var transactions = map[string]*transaction{}
+
+type transaction struct {
+	conn    *pgx.Conn
+	tx      pgx.Tx
+	queries int
+	// audit holds records of queries, written once transaction ends.
+	audit []auditRecord
+}
+
+// RollbackError reports queries rolled back after a synchronization error.
+type RollbackError struct {
+	Err       error
+	Databases []string
+	Queries   int
+}
+
+func (e RollbackError) Error() string {
+	return fmt.Sprintf("%s: rolled back %d queries in %s", e.Err, e.Queries, strings.Join(e.Databases, ", "))
+}
+
+func (e RollbackError) Unwrap() error {
+	return e.Err
+}
+
+// begin opens a transaction on database connection, unless already open.
+//
+// In database mode, commits transactions of other databases first.
+func begin(ctx context.Context, conn *pgx.Conn, database string) error {
+	if _, ok := transactions[database]; ok {
+		return nil
+	}
+	if TransactionMode == "database" {
+		err := Commit(ctx)
+		if err != nil {
+			return err
+		}
+	}
+	slog.Debug("Beginning transaction.", "database", database)
+	tx, err := conn.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	transactions[database] = &transaction{conn: conn, tx: tx}
+	return nil
+}
+
+// rollback transactions after err.
+//
+// In database mode, rolls back transaction of database only. Otherwise, or if
+// database is empty, rolls back all open transactions.
+func rollback(ctx context.Context, database string, err error) error {
+	databases := []string{database}
+	if database == "" || TransactionMode == "all" {
+		databases = slices.Sorted(maps.Keys(transactions))
+	}
+	rerr := RollbackError{Err: err}
+	var errs []error
+	for _, name := range databases {
+		t, ok := transactions[name]
+		if !ok {
+			continue
+		}
+		slog.Warn("Rolling back transaction.", "database", name, "queries", t.queries)
+		errs = append(errs, end(ctx, name, t.tx.Rollback))
+		records := append(rolledBack(t.audit, err), auditRecord{
+			database:    name,
+			description: "Rollback transaction.",
+			args:        map[string]any{"queries": t.queries},
+			sql:         "ROLLBACK;",
+			err:         err,
+		})
+		aerr := CurrentAudit.record(ctx, records...)
+		if aerr != nil {
+			errs = append(errs, fmt.Errorf("audit: %w", aerr))
+		}
+		rerr.Databases = append(rerr.Databases, name)
+		rerr.Queries += t.queries
+	}
+	errs = append(errs, rerr)
+	return errors.Join(errs...)
+}
+
+// Commit all open transactions.
+func Commit(ctx context.Context) error {
+	var errs []error
+	for _, name := range slices.Sorted(maps.Keys(transactions)) {
+		t := transactions[name]
+		slog.Debug("Committing transaction.", "database", name, "queries", t.queries)
+		err := end(ctx, name, t.tx.Commit)
+		records := t.audit
+		if err != nil {
+			errs = append(errs, fmt.Errorf("commit: %s: %w", name, err))
+			records = rolledBack(records, err)
+		}
+		// Audit queries once their outcome is known.
+		err = CurrentAudit.record(ctx, records...)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("audit: %w", err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// abort rolls back open transactions on unexpected error.
+func abort(ctx context.Context, err error) error {
+	if len(transactions) == 0 {
+		return err
+	}
+	return rollback(ctx, "", err)
+}
+
+// end transaction of database with fn and close its connection unless it's
+// the global one.
+func end(ctx context.Context, database string, fn func(context.Context) error) error {
+	t := transactions[database]
+	delete(transactions, database)
+	err := fn(ctx)
+	if t.conn != globalConn {
+		slog.Debug("Closing Postgres connection.", "database", database)
+		_ = t.conn.Close(ctx)
+	}
+	return err
+}
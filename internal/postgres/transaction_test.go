@@ -0,0 +1,43 @@
+package postgres_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/dalibo/ldap2pg/v6/internal/postgres"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRollbackError(t *testing.T) {
+	r := require.New(t)
+
+	cause := errors.New("role does not exist")
+	err := postgres.RollbackError{Err: cause, Databases: []string{"app", "postgres"}, Queries: 3}
+	r.Equal("role does not exist: rolled back 3 queries in app, postgres", err.Error())
+	r.ErrorIs(err, cause)
+
+	_, ok := errors.AsType[postgres.RollbackError](errors.Join(errors.New("other"), err))
+	r.True(ok)
+}
+
+func TestCheckTransactionMode(t *testing.T) {
+	r := require.New(t)
+
+	defer func() {
+		postgres.TransactionMode = ""
+		postgres.Databases = postgres.DBMap{}
+	}()
+	postgres.Databases = postgres.DBMap{"postgres": {Name: "postgres"}}
+	postgres.TransactionMode = "all"
+	r.Nil(postgres.CheckTransactionMode("postgres"))
+
+	postgres.Databases["app"] = postgres.Database{Name: "app"}
+	r.ErrorContains(postgres.CheckTransactionMode("postgres"), "database app besides postgres")
+
+	// Default database is not managed, role queries still run there.
+	delete(postgres.Databases, "postgres")
+	r.ErrorContains(postgres.CheckTransactionMode("postgres"), "database app besides postgres")
+
+	postgres.TransactionMode = "database"
+	r.Nil(postgres.CheckTransactionMode("postgres"))
+}
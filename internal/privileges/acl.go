@@ -27,7 +27,7 @@ func (a ACL) String() string {
 
 // Register ACL
 //
-// scope is one of instance, database, schema.
+// scope is one of instance, database, schema, object.
 // Determines de granularity and relevant fields of the privilege.
 //
 // Grant and Revoke queries may be generated from Name.
@@ -41,6 +41,10 @@ func (a ACL) Register() error {
 		a.rowTo = rowToInstanceGrant
 	case a.Scope == "database":
 		a.rowTo = rowToDatabaseGrant
+	case a.Scope == "object" && a.Uses("arguments"):
+		a.rowTo = rowToFunctionGrant
+	case a.Scope == "object":
+		a.rowTo = rowToObjectGrant
 	default:
 		return fmt.Errorf("unknown scope %q", a.Scope)
 	}
@@ -129,6 +133,16 @@ func rowToDatabaseGrant(r pgx.Row) (g Grant, err error) {
 	return
 }
 
+func rowToObjectGrant(r pgx.Row) (g Grant, err error) {
+	err = r.Scan(&g.Type, &g.Schema, &g.Object, &g.Grantee)
+	return
+}
+
+func rowToFunctionGrant(r pgx.Row) (g Grant, err error) {
+	err = r.Scan(&g.Type, &g.Schema, &g.Object, &g.Arguments, &g.Grantee)
+	return
+}
+
 func NormalizeACLs(yaml any) (any, error) {
 	m, ok := yaml.(map[string]any)
 	if !ok {
@@ -163,6 +177,26 @@ var acls = make(map[string]ACL)
 // Actually, use SplitManagedACLs to synchronize managed ACL by scope.
 var managedACLs = map[string][]string{}
 
+// ObjectsManaged returns whether a managed ACL targets individual objects.
+//
+// Objects must be inspected to expand such grants.
+func ObjectsManaged() bool {
+	for n := range managedACLs {
+		if acls[n].Scope == "object" {
+			return true
+		}
+	}
+	return false
+}
+
+// Reset registries to builtin ACLs, before registering a new configuration.
+func Reset() {
+	acls = make(map[string]ACL)
+	managedACLs = map[string][]string{}
+	profiles = make(map[string]Profile)
+	registerBuiltinACLs()
+}
+
 // SplitManagedACLs by scope
 func SplitManagedACLs() (instancesACLs, databaseACLs, defaultACLs []string) {
 	for n := range managedACLs {
@@ -0,0 +1,36 @@
+package privileges_test
+
+import (
+	"testing"
+
+	"github.com/dalibo/ldap2pg/v6/internal/privileges"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReset(t *testing.T) {
+	r := require.New(t)
+	privileges.Reset()
+
+	err := privileges.ACL{
+		Name:    "CUSTOM",
+		Scope:   "instance",
+		Inspect: "SELECT 1;",
+		Grant:   "GRANT <privilege> ON <database> TO <grantee>;",
+		Revoke:  "REVOKE <privilege> ON <database> FROM <grantee>;",
+	}.Register()
+	r.Nil(err)
+	err = privileges.Profile{{Type: "CONNECT", On: "CUSTOM"}}.Register("custom")
+	r.Nil(err)
+	_, databaseACLs, _ := privileges.SplitManagedACLs()
+	r.Empty(databaseACLs)
+	instanceACLs, _, _ := privileges.SplitManagedACLs()
+	r.Equal([]string{"CUSTOM"}, instanceACLs)
+
+	privileges.Reset()
+	instanceACLs, _, _ = privileges.SplitManagedACLs()
+	r.Empty(instanceACLs)
+	// Custom ACL is unregistered, builtins are kept.
+	r.NotNil(privileges.Profile{{Type: "CONNECT", On: "CUSTOM"}}.Register("custom"))
+	r.Nil(privileges.Profile{{Type: "CONNECT", On: "DATABASE"}}.Register("connect"))
+	privileges.Reset()
+}
@@ -24,11 +24,26 @@ var (
 	inspectAllSequences string
 	//go:embed sql/all-tables.sql
 	inspectAllTables string
+	//go:embed sql/function.sql
+	inspectFunction string
+	//go:embed sql/sequence.sql
+	inspectSequence string
+	//go:embed sql/table.sql
+	inspectTable string
+	//go:embed sql/view.sql
+	inspectView string
 )
 
 func init() {
-	// ACLs
+	registerBuiltinACLs()
 
+	// profiles
+	registerRelationBuiltinProfile("sequences", "select", "update", "usage")
+	registerRelationBuiltinProfile("tables", "delete", "insert", "select", "truncate", "update", "references", "trigger")
+	registerRelationBuiltinProfile("routines", "execute")
+}
+
+func registerBuiltinACLs() {
 	ACL{
 		Name:    "DATABASE",
 		Scope:   "instance",
@@ -84,6 +99,36 @@ func init() {
 		Revoke:  r,
 	}.MustRegister()
 
+	ACL{
+		Name:    "FUNCTION",
+		Scope:   "object",
+		Inspect: inspectFunction,
+		Grant:   `GRANT <privilege> ON <acl> <schema>.<object>(<arguments>) TO <grantee>;`,
+		Revoke:  `REVOKE <privilege> ON <acl> <schema>.<object>(<arguments>) FROM <grantee>;`,
+	}.MustRegister()
+	ACL{
+		Name:    "SEQUENCE",
+		Scope:   "object",
+		Inspect: inspectSequence,
+		Grant:   `GRANT <privilege> ON <acl> <schema>.<object> TO <grantee>;`,
+		Revoke:  `REVOKE <privilege> ON <acl> <schema>.<object> FROM <grantee>;`,
+	}.MustRegister()
+	ACL{
+		Name:    "TABLE",
+		Scope:   "object",
+		Inspect: inspectTable,
+		Grant:   `GRANT <privilege> ON <acl> <schema>.<object> TO <grantee>;`,
+		Revoke:  `REVOKE <privilege> ON <acl> <schema>.<object> FROM <grantee>;`,
+	}.MustRegister()
+	ACL{
+		// GRANT has no VIEW keyword.
+		Name:    "VIEW",
+		Scope:   "object",
+		Inspect: inspectView,
+		Grant:   `GRANT <privilege> ON TABLE <schema>.<object> TO <grantee>;`,
+		Revoke:  `REVOKE <privilege> ON TABLE <schema>.<object> FROM <grantee>;`,
+	}.MustRegister()
+
 	ACL{
 		// implementation is chosed by name instead of scope.
 		Name:    "GLOBAL DEFAULT",
@@ -100,11 +145,6 @@ func init() {
 		Grant:   `ALTER DEFAULT PRIVILEGES FOR ROLE <owner> IN SCHEMA <schema> GRANT <privilege> ON <object> TO <grantee>;`,
 		Revoke:  `ALTER DEFAULT PRIVILEGES FOR ROLE <owner> IN SCHEMA <schema> REVOKE <privilege> ON <object> FROM <grantee>;`,
 	}.MustRegister()
-
-	// profiles
-	registerRelationBuiltinProfile("sequences", "select", "update", "usage")
-	registerRelationBuiltinProfile("tables", "delete", "insert", "select", "truncate", "update", "references", "trigger")
-	registerRelationBuiltinProfile("routines", "execute")
 }
 
 // BuiltinsProfiles holds yaml rewrite for BuiltinsProfiles privileges from v5 format to v6.
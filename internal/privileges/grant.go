@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"log/slog"
 	"maps"
+	"path"
 	"regexp"
 	"slices"
 	"strings"
@@ -23,14 +24,15 @@ import (
 // meaning of Object field changes to hold the privilege class : TABLES,
 // SEQUENCES, etc. instead of the name of an object.
 type Grant struct {
-	Owner    string // For default privileges. Empty otherwise.
-	Grantee  string
-	ACL      string // Name of the referenced ACL: DATABASE, TABLES, etc.
-	Type     string // Privilege type (USAGE, SELECT, etc.)
-	Database string // "" for instance grant.
-	Schema   string // "" for database grant.
-	Object   string // "" for both schema and database grants.
-	Partial  bool   // Used for ALL TABLES permissions.
+	Owner     string // For default privileges. Empty otherwise.
+	Grantee   string
+	ACL       string // Name of the referenced ACL: DATABASE, TABLES, etc.
+	Type      string // Privilege type (USAGE, SELECT, etc.)
+	Database  string // "" for instance grant.
+	Schema    string // "" for database grant.
+	Object    string // "" for both schema and database grants.
+	Arguments string // Argument types of FUNCTION. Empty otherwise.
+	Partial   bool   // Used for ALL TABLES permissions.
 }
 
 func (g Grant) IsWildcard() bool {
@@ -55,6 +57,8 @@ func (g Grant) FormatQuery(s string) (q postgres.SyncQuery) {
 	// Replace keywords in query.
 	s = strings.ReplaceAll(s, "<privilege>", g.Type)
 	s = strings.ReplaceAll(s, "<acl>", g.ACL)
+	// Argument types are formatted by Postgres.
+	s = strings.ReplaceAll(s, "<arguments>", strings.ReplaceAll(g.Arguments, "%", "%%"))
 	if strings.Contains(s, "<owner>") {
 		// default privileges are by design on keywords like TABLES, not identiers.
 		s = strings.ReplaceAll(s, "<object>", g.Object)
@@ -124,6 +128,9 @@ func (g Grant) String() string {
 				}
 				o.WriteString(g.Object)
 			}
+			if acls[g.ACL].Uses("arguments") {
+				o.WriteString("(" + g.Arguments + ")")
+			}
 		}
 		b.WriteString(o.String())
 	}
@@ -216,20 +223,43 @@ func (g Grant) ExpandSchemas(schemas []string) (out []Grant) {
 	return
 }
 
+// ExpandObjects instantiates grant on individual objects matching Object
+// glob pattern.
+func (g Grant) ExpandObjects(database postgres.Database) (out []Grant) {
+	if acls[g.ACL].Scope != "object" {
+		out = append(out, g)
+		return
+	}
+
+	for _, o := range database.Schemas[g.Schema].Objects {
+		if o.Kind != g.ACL {
+			continue
+		}
+		ok, err := path.Match(g.Object, o.Name)
+		if err != nil {
+			slog.Warn("Bad object pattern.", "pattern", g.Object, "err", err)
+			return nil
+		}
+		if !ok {
+			continue
+		}
+		g := g // copy
+		g.Object = o.Name
+		g.Arguments = o.Arguments
+		out = append(out, g)
+	}
+
+	return
+}
+
 // Expand grants from rules.
 //
 // e.g.: instantiate a grant on all databases for each database.
-// Same for schemas and owners.
+// Same for schemas, objects and owners.
 func Expand(in []Grant, database postgres.Database) (out []Grant) {
+	in = expandSchemas(in, database)
 	for _, grant := range in {
-		out = append(out, grant.ExpandDatabase(database.Name)...)
-	}
-
-	in = out
-	out = nil
-	schemas := slices.Collect(maps.Keys(database.Schemas))
-	for _, grant := range in {
-		out = append(out, grant.ExpandSchemas(schemas)...)
+		out = append(out, grant.ExpandObjects(database)...)
 	}
 
 	in = out
@@ -244,3 +274,19 @@ func Expand(in []Grant, database postgres.Database) (out []Grant) {
 
 	return
 }
+
+// expandSchemas instantiates grants for database and schemas, keeping object
+// patterns.
+func expandSchemas(in []Grant, database postgres.Database) (out []Grant) {
+	for _, grant := range in {
+		out = append(out, grant.ExpandDatabase(database.Name)...)
+	}
+
+	in = out
+	out = nil
+	schemas := slices.Collect(maps.Keys(database.Schemas))
+	for _, grant := range in {
+		out = append(out, grant.ExpandSchemas(schemas)...)
+	}
+	return
+}
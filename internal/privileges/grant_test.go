@@ -227,3 +227,57 @@ func TestFormatDefaultQuery(t *testing.T) {
 	r.Equal(t, `ADP FOR %s IN SCHEMA %s GRANT SELECT ON TABLES TO %s;`, q.Query)
 	r.Len(t, q.QueryArgs, 3)
 }
+
+func TestExpandObjects(t *testing.T) {
+	Reset()
+	defer Reset()
+
+	db := postgres.Database{
+		Name: "db0",
+		Schemas: map[string]postgres.Schema{
+			"reporting": {
+				Name: "reporting",
+				Objects: []postgres.Object{
+					{Schema: "reporting", Kind: "TABLE", Name: "sales_2023"},
+					{Schema: "reporting", Kind: "TABLE", Name: "sales_2024"},
+					{Schema: "reporting", Kind: "TABLE", Name: "stock"},
+					{Schema: "reporting", Kind: "VIEW", Name: "sales_total"},
+					{Schema: "reporting", Kind: "FUNCTION", Name: "sales_of", Arguments: "integer, text"},
+				},
+			},
+		},
+	}
+
+	g := Grant{
+		ACL:      "TABLE",
+		Type:     "SELECT",
+		Grantee:  "alice",
+		Database: "db0",
+		Schema:   "reporting",
+		Object:   "sales_*",
+	}
+	grants := g.ExpandObjects(db)
+	r.Len(t, grants, 2)
+	r.Equal(t, "sales_2023", grants[0].Object)
+	r.Equal(t, "sales_2024", grants[1].Object)
+
+	g.Object = "missing"
+	r.Empty(t, g.ExpandObjects(db))
+
+	g.ACL = "FUNCTION"
+	g.Type = "EXECUTE"
+	g.Object = "*"
+	grants = g.ExpandObjects(db)
+	r.Len(t, grants, 1)
+	r.Equal(t, "sales_of", grants[0].Object)
+	r.Equal(t, "integer, text", grants[0].Arguments)
+	r.Equal(t, "EXECUTE ON FUNCTION reporting.sales_of(integer, text) TO alice", grants[0].String())
+
+	q := grants[0].FormatQuery(acls["FUNCTION"].Grant)
+	r.Equal(t, `GRANT EXECUTE ON FUNCTION %s.%s(integer, text) TO %s;`, q.Query)
+	r.Len(t, q.QueryArgs, 3)
+
+	// Grant on schema is not expanded.
+	g = Grant{ACL: "SCHEMA", Type: "USAGE", Schema: "reporting"}
+	r.Equal(t, []Grant{g}, g.ExpandObjects(db))
+}
@@ -20,6 +20,7 @@ func NormalizeGrantRule(yaml any) (rule map[string]any, err error) {
 		"owners":    "__auto__",
 		"schemas":   "__all__",
 		"databases": "__all__",
+		"objects":   "*",
 	}
 
 	yamlMap, ok := yaml.(map[string]any)
@@ -43,6 +44,10 @@ func NormalizeGrantRule(yaml any) (rule map[string]any, err error) {
 	if err != nil {
 		return
 	}
+	err = normalize.Alias(yamlMap, "objects", "object")
+	if err != nil {
+		return
+	}
 	err = normalize.Alias(yamlMap, "roles", "to")
 	if err != nil {
 		return
@@ -58,7 +63,7 @@ func NormalizeGrantRule(yaml any) (rule map[string]any, err error) {
 
 	maps.Copy(rule, yamlMap)
 
-	keys := []string{"owners", "privileges", "databases", "schemas", "roles"}
+	keys := []string{"owners", "privileges", "databases", "schemas", "objects", "roles"}
 	for _, k := range keys {
 		rule[k], err = normalize.StringList(rule[k])
 		if err != nil {
@@ -71,7 +76,7 @@ func NormalizeGrantRule(yaml any) (rule map[string]any, err error) {
 
 // DuplicateGrantRules split plurals for mapstructure
 func DuplicateGrantRules(yaml map[string]any) (rules []any) {
-	keys := []string{"owners", "databases", "schemas", "roles", "privileges"}
+	keys := []string{"owners", "databases", "schemas", "objects", "roles", "privileges"}
 	keys = lists.Filter(keys, func(s string) bool {
 		return len(yaml[s].([]string)) > 0
 	})
@@ -97,6 +102,7 @@ type GrantRule struct {
 	Privilege pyfmt.Format
 	Database  pyfmt.Format
 	Schema    pyfmt.Format
+	Object    pyfmt.Format
 	To        pyfmt.Format `mapstructure:"role"`
 }
 
@@ -105,7 +111,7 @@ func (r GrantRule) IsStatic() bool {
 }
 
 func (r GrantRule) Formats() []pyfmt.Format {
-	return []pyfmt.Format{r.Owner, r.Privilege, r.Database, r.Schema, r.To}
+	return []pyfmt.Format{r.Owner, r.Privilege, r.Database, r.Schema, r.Object, r.To}
 }
 
 func (r GrantRule) Generate(results *ldap.Result) <-chan Grant {
@@ -121,7 +127,7 @@ func (r GrantRule) Generate(results *ldap.Result) <-chan Grant {
 			close(vchanw)
 			vchan = vchanw
 		} else {
-			vchan = results.GenerateValues(r.Owner, r.Privilege, r.Database, r.Schema, r.To)
+			vchan = results.GenerateValues(r.Owner, r.Privilege, r.Database, r.Schema, r.Object, r.To)
 		}
 
 		for values := range vchan {
@@ -142,7 +148,13 @@ func (r GrantRule) Generate(results *ldap.Result) <-chan Grant {
 					grant.Schema = r.Schema.Format(values)
 				}
 
-				if acl.Uses("object") {
+				if acl.Scope == "object" {
+					// Object pattern may be qualified with schema.
+					grant.Object = r.Object.Format(values)
+					if schema, object, ok := strings.Cut(grant.Object, "."); ok {
+						grant.Schema, grant.Object = schema, object
+					}
+				} else if acl.Uses("object") {
 					grant.Object = priv.Object
 				}
 
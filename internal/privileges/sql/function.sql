@@ -0,0 +1,14 @@
+SELECT
+	grt.privilege_type AS "privilege",
+	nspname AS "schema",
+	proname AS "object",
+	pg_catalog.oidvectortypes(pro.proargtypes) AS "arguments",
+	COALESCE(rolname, 'public') AS grantee
+FROM pg_catalog.pg_proc AS pro
+JOIN pg_catalog.pg_namespace AS nsp ON nsp.oid = pro.pronamespace
+NATURAL JOIN aclexplode(COALESCE(pro.proacl, acldefault('f', pro.proowner))) AS grt
+LEFT OUTER JOIN pg_catalog.pg_roles AS grantee ON grantee.oid = grt.grantee
+-- Exclude procedures.
+WHERE pg_catalog.pg_get_function_result(pro.oid) IS NOT NULL
+	AND grt.privilege_type = ANY ($1)
+ORDER BY 2, 3, 4, 1, 5
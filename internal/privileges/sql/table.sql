@@ -0,0 +1,12 @@
+SELECT
+	grt.privilege_type AS "privilege",
+	nspname AS "schema",
+	relname AS "object",
+	COALESCE(rolname, 'public') AS grantee
+FROM pg_catalog.pg_class AS rel
+JOIN pg_catalog.pg_namespace AS nsp ON nsp.oid = rel.relnamespace
+NATURAL JOIN aclexplode(COALESCE(rel.relacl, acldefault('r', rel.relowner))) AS grt
+LEFT OUTER JOIN pg_catalog.pg_roles AS grantee ON grantee.oid = grt.grantee
+WHERE rel.relkind IN ('r', 'p', 'f')
+	AND grt.privilege_type = ANY ($1)
+ORDER BY 2, 3, 1, 4
@@ -2,34 +2,33 @@ package privileges
 
 import (
 	"context"
+	"log/slog"
+	"path"
+	"slices"
 
 	"github.com/dalibo/ldap2pg/v6/internal/postgres"
 	mapset "github.com/deckarep/golang-set/v2"
 )
 
-func Sync(ctx context.Context, really bool, dbname string, current, wanted []Grant) (int, error) {
-	wanted = Expand(wanted, postgres.Databases[dbname])
-	queries := diff(current, wanted)
+// Sync applies changes from current to wanted grants.
+//
+// wanted grants must be expanded. If revoke is false, spurious grants are
+// kept.
+func Sync(ctx context.Context, really, revoke bool, current, wanted []Grant) (int, error) {
+	queries := diff(current, wanted, revoke)
 	return postgres.Apply(ctx, queries, really)
 }
 
-func diff(current, wanted []Grant) <-chan postgres.SyncQuery {
+func diff(current, wanted []Grant, revoke bool) <-chan postgres.SyncQuery {
 	ch := make(chan postgres.SyncQuery)
 	go func() {
 		defer close(ch)
-		wantedSet := mapset.NewSet(wanted...)
 		// Revoke spurious grants.
-		for _, grant := range current {
-			wantedGrant := grant
-			// Always search a full grant in wanted. If we have a
-			// partial grant in instance, it will be regranted in
-			// grant loop.
-			wantedGrant.Partial = false
-			// Don't revoke irrelevant ANY ... IN SCHEMA
-			if wantedSet.Contains(wantedGrant) || grant.Type == "" {
+		for _, grant := range Revokes(current, wanted) {
+			if !revoke {
+				slog.Warn("Not revoking privileges.", "grant", grant, "database", grant.Database)
 				continue
 			}
-
 			q := grant.FormatQuery(acls[grant.ACL].Revoke)
 			q.Description = "Revoke privileges."
 			q.Database = grant.Database
@@ -61,3 +60,97 @@ func diff(current, wanted []Grant) <-chan postgres.SyncQuery {
 	}()
 	return ch
 }
+
+// Revokes returns current grants missing from wanted grants.
+func Revokes(current, wanted []Grant) (out []Grant) {
+	wantedSet := mapset.NewSet(wanted...)
+	for _, grant := range current {
+		wantedGrant := grant
+		// Always search a full grant in wanted. If we have a
+		// partial grant in instance, it will be regranted in
+		// grant loop.
+		wantedGrant.Partial = false
+		// Don't revoke irrelevant ANY ... IN SCHEMA
+		if wantedSet.Contains(wantedGrant) || grant.Type == "" {
+			continue
+		}
+		out = append(out, grant)
+	}
+	return
+}
+
+// allInSchema maps ACLs on individual objects to ACLs granting on all objects
+// of a schema.
+var allInSchema = map[string][]string{
+	"FUNCTION": {"ALL FUNCTIONS IN SCHEMA", "ALL ROUTINES IN SCHEMA"},
+	"SEQUENCE": {"ALL SEQUENCES IN SCHEMA"},
+	"TABLE":    {"ALL TABLES IN SCHEMA"},
+	"VIEW":     {"ALL TABLES IN SCHEMA"},
+}
+
+// Managed filters current grants of acl to grants ldap2pg is responsible for.
+//
+// A grant on an individual object is managed if the object matches an object
+// pattern of wanted grants and if no wanted ALL ... IN SCHEMA grant covers it.
+// A partial ALL ... IN SCHEMA grant is not managed if wanted grants on
+// individual objects of the schema explain it.
+//
+// wanted holds grants from rules by ACL, before expansion.
+func Managed(acl string, current []Grant, database postgres.Database, wanted map[string][]Grant) (out []Grant) {
+	if acls[acl].Scope == "object" {
+		patterns := expandSchemas(wanted[acl], database)
+		covering := mapset.NewSet[Grant]()
+		for _, name := range allInSchema[acl] {
+			covering.Append(Expand(wanted[name], database)...)
+		}
+		for _, g := range current {
+			if !slices.ContainsFunc(patterns, g.matches) {
+				continue
+			}
+			if slices.ContainsFunc(allInSchema[acl], func(name string) bool {
+				return covering.Contains(g.schemaWide(name))
+			}) {
+				continue
+			}
+			out = append(out, g)
+		}
+		return
+	}
+
+	var objectWanted []Grant
+	for objectACL, names := range allInSchema {
+		if slices.Contains(names, acl) {
+			objectWanted = append(objectWanted, expandSchemas(wanted[objectACL], database)...)
+		}
+	}
+	for _, g := range current {
+		if g.Partial && slices.ContainsFunc(objectWanted, func(o Grant) bool {
+			return g.schemaWide(acl) == o.schemaWide(acl)
+		}) {
+			continue
+		}
+		out = append(out, g)
+	}
+	return
+}
+
+// matches returns whether g targets an object matching the object pattern of
+// wanted grant w.
+func (g Grant) matches(w Grant) bool {
+	if g.Database != w.Database || g.Schema != w.Schema {
+		return false
+	}
+	ok, _ := path.Match(w.Object, g.Object)
+	return ok
+}
+
+// schemaWide returns grant g on all objects of its schema with acl.
+func (g Grant) schemaWide(acl string) Grant {
+	return Grant{
+		ACL:      acl,
+		Grantee:  g.Grantee,
+		Type:     g.Type,
+		Database: g.Database,
+		Schema:   g.Schema,
+	}
+}
@@ -0,0 +1,94 @@
+package privileges
+
+import (
+	"testing"
+
+	"github.com/dalibo/ldap2pg/v6/internal/postgres"
+	r "github.com/stretchr/testify/require"
+)
+
+func TestRevokes(t *testing.T) {
+	connect := Grant{ACL: "DATABASE", Grantee: "alice", Type: "CONNECT", Database: "db"}
+	create := Grant{ACL: "DATABASE", Grantee: "alice", Type: "CREATE", Database: "db"}
+	partial := create
+	partial.Partial = true
+	// ANY ... IN SCHEMA is never revoked.
+	wildcard := Grant{ACL: "DATABASE", Grantee: "public", Database: "db"}
+
+	revokes := Revokes([]Grant{connect, partial, wildcard}, []Grant{create})
+	r.Equal(t, []Grant{connect}, revokes)
+}
+
+func TestDiffKeepRevokes(t *testing.T) {
+	connect := Grant{ACL: "DATABASE", Grantee: "alice", Type: "CONNECT", Database: "db"}
+	create := Grant{ACL: "DATABASE", Grantee: "alice", Type: "CREATE", Database: "db"}
+
+	var descriptions []string
+	for q := range diff([]Grant{connect}, []Grant{create}, true) {
+		descriptions = append(descriptions, q.Description)
+	}
+	r.Equal(t, []string{"Revoke privileges.", "Grant privileges."}, descriptions)
+
+	descriptions = nil
+	for q := range diff([]Grant{connect}, []Grant{create}, false) {
+		descriptions = append(descriptions, q.Description)
+	}
+	r.Equal(t, []string{"Grant privileges."}, descriptions)
+}
+
+func TestManagedObjects(t *testing.T) {
+	db := postgres.Database{
+		Name: "db",
+		Schemas: map[string]postgres.Schema{
+			"public": {
+				Name: "public",
+				Objects: []postgres.Object{
+					{Schema: "public", Kind: "TABLE", Name: "sales"},
+					{Schema: "public", Kind: "TABLE", Name: "stock"},
+				},
+			},
+		},
+	}
+	postgres.Databases["db"] = db
+	defer delete(postgres.Databases, "db")
+	// __select_on_all_tables__ to alice and SELECT on sales to bob.
+	wanted := map[string][]Grant{
+		"ALL TABLES IN SCHEMA": {{ACL: "ALL TABLES IN SCHEMA", Grantee: "alice", Type: "SELECT", Database: "__all__", Schema: "__all__"}},
+		"TABLE":                {{ACL: "TABLE", Grantee: "bob", Type: "SELECT", Database: "__all__", Schema: "__all__", Object: "sal*"}},
+	}
+
+	// First run grants on an empty database.
+	var queries []string
+	for _, acl := range []string{"ALL TABLES IN SCHEMA", "TABLE"} {
+		for q := range diff(Managed(acl, nil, db, wanted), Expand(wanted[acl], db), true) {
+			queries = append(queries, q.Description)
+		}
+	}
+	r.Equal(t, []string{"Grant privileges.", "Grant privileges."}, queries)
+
+	// Second run inspects grants on tables made by both ACLs, and a grant
+	// on an unmanaged table.
+	current := map[string][]Grant{
+		"ALL TABLES IN SCHEMA": {
+			{ACL: "ALL TABLES IN SCHEMA", Grantee: "alice", Type: "SELECT", Database: "db", Schema: "public"},
+			{ACL: "ALL TABLES IN SCHEMA", Grantee: "bob", Type: "SELECT", Database: "db", Schema: "public", Partial: true},
+		},
+		"TABLE": {
+			{ACL: "TABLE", Grantee: "alice", Type: "SELECT", Database: "db", Schema: "public", Object: "sales"},
+			{ACL: "TABLE", Grantee: "alice", Type: "SELECT", Database: "db", Schema: "public", Object: "stock"},
+			{ACL: "TABLE", Grantee: "bob", Type: "SELECT", Database: "db", Schema: "public", Object: "sales"},
+			{ACL: "TABLE", Grantee: "carol", Type: "SELECT", Database: "db", Schema: "public", Object: "stock"},
+		},
+	}
+	queries = nil
+	for _, acl := range []string{"ALL TABLES IN SCHEMA", "TABLE"} {
+		for q := range diff(Managed(acl, current[acl], db, wanted), Expand(wanted[acl], db), true) {
+			queries = append(queries, q.Description)
+		}
+	}
+	r.Empty(t, queries)
+
+	// Spurious grant on a matching table is revoked.
+	current["TABLE"] = append(current["TABLE"], Grant{ACL: "TABLE", Grantee: "carol", Type: "SELECT", Database: "db", Schema: "public", Object: "sales"})
+	r.Equal(t, current["TABLE"][4:], Revokes(Managed("TABLE", current["TABLE"], db, wanted), Expand(wanted["TABLE"], db)))
+}
@@ -3,6 +3,7 @@ package pyfmt
 import (
 	"errors"
 	"fmt"
+	"strconv"
 	"strings"
 
 	mapset "github.com/deckarep/golang-set/v2"
@@ -107,7 +108,7 @@ func parseField(s string) (f Field) {
 		f.FieldName = before
 	}
 	f.FormatSpec = after
-	if strings.HasSuffix(f.FieldName, "()") {
+	if strings.HasSuffix(f.FieldName, ")") {
 		lastPoint := strings.LastIndex(f.FieldName, ".")
 		f.Method = f.FieldName[lastPoint+1:]
 		f.FieldName = f.FieldName[:lastPoint]
@@ -143,7 +144,7 @@ func (f Format) Format(values map[string]string) string {
 			case "string()":
 				v = fmt.Sprintf("'%s'", strings.ReplaceAll(v, "'", "''"))
 			default:
-				v = "!INVALID_METHOD"
+				v = formatFlag(f.Method, v)
 			}
 			b.WriteString(v)
 		}
@@ -151,6 +152,26 @@ func (f Format) Format(values map[string]string) string {
 	return b.String()
 }
 
+// formatFlag renders flag(mask) and noflag(mask) methods.
+//
+// flag(mask) is true if integer value has all bits of mask set, like
+// userAccountControl.flag(2) for disabled Active Directory account.
+// noflag(mask) is the negation.
+func formatFlag(method, v string) string {
+	name, arg, ok := strings.Cut(strings.TrimSuffix(method, ")"), "(")
+	if !ok || name != "flag" && name != "noflag" {
+		return "!INVALID_METHOD"
+	}
+	mask, err := strconv.ParseInt(arg, 0, 64)
+	if err != nil {
+		return "!INVALID_METHOD"
+	}
+	// Unparsable value has no flag.
+	i, _ := strconv.ParseInt(v, 10, 64)
+	set := i&mask == mask
+	return strconv.FormatBool(set == (name == "flag"))
+}
+
 func (f Format) String() string {
 	return f.Input
 }
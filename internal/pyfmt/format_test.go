@@ -112,6 +112,22 @@ func (suite *Suite) TestFormat() {
 	r.Equal("ext_dba_ALICE", s)
 }
 
+func (suite *Suite) TestFormatFlag() {
+	r := suite.Require()
+
+	f, err := pyfmt.Parse("{userAccountControl.flag(2)} {userAccountControl.noflag(0x2)}")
+	r.Nil(err)
+	r.Equal("userAccountControl", f.Fields[0].FieldName)
+	r.Equal("flag(2)", f.Fields[0].Method)
+
+	r.Equal("true false", f.Format(map[string]string{"userAccountControl": "514"}))
+	r.Equal("false true", f.Format(map[string]string{"userAccountControl": "512"}))
+
+	f, err = pyfmt.Parse("{userAccountControl.flag(x)}")
+	r.Nil(err)
+	r.Equal("!INVALID_METHOD", f.Format(map[string]string{"userAccountControl": "512"}))
+}
+
 func Test(t *testing.T) {
 	if testing.Verbose() {
 		internal.SetLoggingHandler(slog.LevelDebug, false)
@@ -1,6 +1,13 @@
 package role
 
-import "strings"
+import (
+	"maps"
+	"slices"
+	"strings"
+
+	"github.com/dalibo/ldap2pg/v6/internal/postgres"
+	"github.com/jackc/pgx/v5"
+)
 
 type Config map[string]string
 
@@ -13,3 +20,50 @@ func (c Config) Parse(rows []string) {
 		c[parts[0]] = parts[1]
 	}
 }
+
+// alter generates queries to change current config c of role name to wanted.
+//
+// An empty database means config in all databases.
+func (c Config) alter(name, database string, wanted Config) (out []postgres.SyncQuery) {
+	prefix := `ALTER ROLE %s`
+	args := []any{pgx.Identifier{name}}
+	logArgs := []any{"role", name}
+	if database != "" {
+		prefix += ` IN DATABASE %s`
+		args = append(args, pgx.Identifier{database})
+		logArgs = append(logArgs, "database", database)
+	}
+
+	for _, k := range slices.Sorted(maps.Keys(c)) {
+		if _, ok := wanted[k]; ok {
+			continue
+		}
+		out = append(out, postgres.SyncQuery{
+			Description: "Reset role config.",
+			LogArgs:     append(slices.Clone(logArgs), "config", k),
+			Query:       prefix + ` RESET %s;`,
+			QueryArgs:   append(slices.Clone(args), pgx.Identifier{k}),
+		})
+	}
+
+	for _, k := range slices.Sorted(maps.Keys(wanted)) {
+		wantedValue := wanted[k]
+		currentValue, ok := c[k]
+		if ok && currentValue == wantedValue {
+			continue
+		}
+		description := "Set role config."
+		values := []any{"value", wantedValue}
+		if ok {
+			description = "Update role config."
+			values = []any{"current", currentValue, "wanted", wantedValue}
+		}
+		out = append(out, postgres.SyncQuery{
+			Description: description,
+			LogArgs:     append(append(slices.Clone(logArgs), "config", k), values...),
+			Query:       prefix + ` SET %s TO %s;`,
+			QueryArgs:   append(slices.Clone(args), pgx.Identifier{k}, wantedValue),
+		})
+	}
+	return
+}
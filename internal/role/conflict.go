@@ -0,0 +1,84 @@
+package role
+
+import (
+	"slices"
+)
+
+// Conflicts lists attributes defined with different values in r and o.
+//
+// Compares comment, hooks and options managed by both roles.
+func (r Role) Conflicts(o Role) (out []string) {
+	if r.Comment != o.Comment {
+		out = append(out, "comment")
+	}
+	for _, token := range optionTokens() {
+		if !r.Options.IsManaged(token) || !o.Options.IsManaged(token) {
+			continue
+		}
+		if !r.Options.field(token).Equal(o.Options.field(token)) {
+			out = append(out, token)
+		}
+	}
+	if r.BeforeCreate != o.BeforeCreate {
+		out = append(out, "before_create")
+	}
+	if r.AfterCreate != o.AfterCreate {
+		out = append(out, "after_create")
+	}
+	if r.AfterAlter != o.AfterAlter {
+		out = append(out, "after_alter")
+	}
+	return
+}
+
+// Override sets conflicting attributes of r from o.
+func (r *Role) Override(o Role) {
+	for _, attribute := range r.Conflicts(o) {
+		switch attribute {
+		case "comment":
+			r.Comment = o.Comment
+		case "before_create":
+			r.BeforeCreate = o.BeforeCreate
+		case "after_create":
+			r.AfterCreate = o.AfterCreate
+		case "after_alter":
+			r.AfterAlter = o.AfterAlter
+		default:
+			r.Options.field(attribute).Set(o.Options.field(attribute))
+		}
+	}
+}
+
+// Permit grants conflicting options of o to r.
+//
+// Boolean options are true if true in any role. Connection limit is the
+// highest, -1 meaning no limit. Other attributes are unchanged.
+func (r *Role) Permit(o Role) {
+	conflicts := r.Conflicts(o)
+	for _, token := range optionTokens() {
+		if !slices.Contains(conflicts, token) {
+			continue
+		}
+		field := r.Options.field(token)
+		other := o.Options.field(token)
+		switch {
+		case field.CanInt() && (field.Int() == -1 || other.Int() == -1):
+			field.SetInt(-1)
+		case field.CanInt():
+			field.SetInt(max(field.Int(), other.Int()))
+		default:
+			field.SetBool(field.Bool() || other.Bool())
+		}
+	}
+}
+
+// mergeOptions copies options managed by o but unmanaged by r.
+func (r *Role) mergeOptions(o Role) {
+	for _, token := range optionTokens() {
+		if r.Options.IsManaged(token) || !o.Options.IsManaged(token) {
+			continue
+		}
+		r.Options.field(token).Set(o.Options.field(token))
+		r.Options.Manage(token)
+	}
+}
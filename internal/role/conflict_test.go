@@ -0,0 +1,40 @@
+package role_test
+
+import (
+	"testing"
+
+	"github.com/dalibo/ldap2pg/v6/internal/role"
+	"github.com/stretchr/testify/require"
+)
+
+func TestConflicts(t *testing.T) {
+	r := require.New(t)
+
+	first := role.Role{Name: "alice", Comment: "Developer"}
+	first.Options.CanLogin = true
+	first.Options.ConnLimit = 5
+	first.Options.Managed = []string{"LOGIN", "CONNECTION LIMIT"}
+
+	other := role.Role{Name: "alice", Comment: "Analyst"}
+	other.Options.ConnLimit = -1
+	other.Options.Super = true
+	other.Options.Managed = []string{"SUPERUSER", "CONNECTION LIMIT"}
+
+	r.Equal([]string{"comment", "CONNECTION LIMIT"}, first.Conflicts(other))
+
+	last := first
+	last.Override(other)
+	r.Equal("Analyst", last.Comment)
+	r.Equal(-1, last.Options.ConnLimit)
+
+	permissive := first
+	permissive.Permit(other)
+	r.Equal("Developer", permissive.Comment)
+	r.Equal(-1, permissive.Options.ConnLimit)
+
+	// Merge takes options unmanaged by first.
+	first.Merge(other)
+	r.True(first.Options.Super)
+	r.True(first.Options.IsManaged("SUPERUSER"))
+	r.Equal(5, first.Options.ConnLimit)
+}
@@ -2,50 +2,96 @@ package role
 
 import (
 	"log/slog"
+	"slices"
 
 	"github.com/dalibo/ldap2pg/v6/internal/postgres"
 )
 
-func Diff(all, managed, wanted Map, fallbackOwner string) <-chan postgres.SyncQuery {
+func Diff(all, managed, wanted Map, fallbackOwner string, policy DropPolicy) <-chan postgres.SyncQuery {
 	ch := make(chan postgres.SyncQuery)
 	go func() {
 		defer close(ch)
+		renamed := map[string]Role{}
+		for old, name := range Renames(all, managed, wanted) {
+			renamed[name] = all[old]
+		}
 		// Create missing roles.
 		for _, name := range wanted.Flatten() {
 			role := wanted[name]
-			if other, ok := all[name]; ok {
+			if other, ok := renamed[name]; ok {
+				slog.Info("Renaming role with same id.", "role", other.Name, "name", name, "id", other.ID)
+				sendQueries(other.Rename(name), ch)
+				other.Name = name
+				sendQueries(other.Alter(policy.keepParents(role, other)), ch)
+			} else if other, ok := all[name]; ok {
 				// Check for existing role, even if unmanaged.
 				if _, ok := managed[name]; !ok {
 					slog.Warn("Reusing unmanaged role. Ensure managed_roles_query returns all wanted roles.", "role", name)
 				}
-				sendQueries(other.Alter(role), ch)
+				if _, ok := other.DisabledSince(); ok {
+					slog.Info("Re-enabling disabled role.", "role", name)
+					if other.DisabledLogin() && !role.Options.IsManaged("LOGIN") {
+						// Restore LOGIN revoked by disable.
+						role.Options.Manage("LOGIN")
+						role.Options.CanLogin = true
+					}
+				}
+				sendQueries(other.Alter(policy.keepParents(role, other)), ch)
 			} else {
 				sendQueries(role.Create(), ch)
 			}
 		}
 
 		// Drop spurious roles.
-		// Only from managed roles.
-		for name := range managed {
-			if _, ok := wanted[name]; ok {
-				continue
-			}
+		for _, name := range Spurious(all, managed, wanted) {
+			sendQueries(policy.remove(all[name], all, fallbackOwner), ch)
+		}
+	}()
+	return ch
+}
 
-			if name == "public" {
-				continue
-			}
+// keepParents adds current parents missing from wanted role if policy keeps
+// parents.
+func (p DropPolicy) keepParents(wanted, current Role) Role {
+	if !p.KeepParents {
+		return wanted
+	}
+	kept := wanted.MissingParents(current.Parents)
+	for _, m := range kept {
+		slog.Warn("Not revoking parent.", "role", current.Name, "parent", m.Name)
+	}
+	wanted.Parents = append(slices.Clone(wanted.Parents), kept...)
+	return wanted
+}
 
-			role, ok := all[name]
-			if !ok {
-				// Already dropped. ldap2pg hits this case whan
-				// ManagedRoles is static.
-				continue
-			}
+// Spurious returns the names of roles to drop.
+//
+// Only managed roles are dropped. Roles renamed by Renames are kept.
+func Spurious(all, managed, wanted Map) (names []string) {
+	renames := Renames(all, managed, wanted)
+	for name := range managed {
+		if _, ok := wanted[name]; ok {
+			continue
+		}
 
-			sendQueries(role.Drop(fallbackOwner), ch)
+		if _, ok := renames[name]; ok {
+			continue
 		}
-	}()
-	return ch
+
+		if name == "public" {
+			continue
+		}
+
+		if _, ok := all[name]; !ok {
+			// Already dropped. ldap2pg hits this case whan
+			// ManagedRoles is static.
+			continue
+		}
+
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	return
 }
 
 func sendQueries(queries []postgres.SyncQuery, ch chan postgres.SyncQuery) {
@@ -0,0 +1,56 @@
+package role_test
+
+import (
+	"testing"
+
+	"github.com/dalibo/ldap2pg/v6/internal/role"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSpurious(t *testing.T) {
+	r := require.New(t)
+
+	all := role.Map{
+		"alice":    role.Role{Name: "alice"},
+		"bob":      role.Role{Name: "bob"},
+		"carol":    role.Role{Name: "carol"},
+		"postgres": role.Role{Name: "postgres"},
+	}
+	managed := role.Map{
+		"public": role.Role{Name: "public"},
+		"alice":  role.Role{Name: "alice"},
+		"bob":    role.Role{Name: "bob"},
+		"carol":  role.Role{Name: "carol"},
+		"dave":   role.Role{Name: "dave"},
+	}
+	wanted := role.Map{"alice": role.Role{Name: "alice"}}
+
+	r.Equal([]string{"bob", "carol"}, role.Spurious(all, managed, wanted))
+}
+
+func TestDiffKeepParents(t *testing.T) {
+	r := require.New(t)
+
+	all := role.Map{
+		"alice":   role.Role{Name: "alice", Parents: []role.Membership{{Name: "readers", Grantor: "postgres"}}},
+		"readers": role.Role{Name: "readers"},
+		"writers": role.Role{Name: "writers"},
+	}
+	wanted := role.Map{
+		"alice":   role.Role{Name: "alice", Parents: []role.Membership{{Name: "writers"}}},
+		"readers": role.Role{Name: "readers"},
+		"writers": role.Role{Name: "writers"},
+	}
+
+	var descriptions []string
+	for q := range role.Diff(all, all, wanted, "postgres", role.DropPolicy{}) {
+		descriptions = append(descriptions, q.Description)
+	}
+	r.Equal([]string{"Grant missing parents.", "Revoke spurious parent."}, descriptions)
+
+	descriptions = nil
+	for q := range role.Diff(all, all, wanted, "postgres", role.DropPolicy{KeepParents: true}) {
+		descriptions = append(descriptions, q.Description)
+	}
+	r.Equal([]string{"Grant missing parents."}, descriptions)
+}
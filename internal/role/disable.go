@@ -0,0 +1,206 @@
+package role
+
+import (
+	"encoding/json"
+	"log/slog"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/dalibo/ldap2pg/v6/internal/postgres"
+	"github.com/dalibo/ldap2pg/v6/internal/pyfmt"
+	"github.com/jackc/pgx/v5"
+)
+
+// disabledPrefix marks comment of roles disabled by ldap2pg.
+const disabledPrefix = "ldap2pg: disabled since "
+
+// loginMarker follows disable timestamp in comment marker if disable revoked
+// LOGIN.
+const loginMarker = ", login revoked"
+
+// revokedSeparator precedes parents revoked by disable in comment marker.
+const revokedSeparator = ", revoked "
+
+// DropPolicy configures how Diff removes spurious roles.
+type DropPolicy struct {
+	// Disable spurious roles instead of dropping them. Drop disabled roles
+	// after GracePeriod.
+	Disable     bool
+	GracePeriod time.Duration
+	Now         time.Time
+	// ReassignTo renders new owner of objects of dropped role from name and
+	// parent variables. Empty means database owner.
+	ReassignTo pyfmt.Format
+	// BeforeDrop and AfterDrop render hooks from name, comment and id
+	// variables.
+	BeforeDrop HookFormat
+	AfterDrop  HookFormat
+	// KeepParents skips revoking memberships missing from wanted roles.
+	KeepParents bool
+}
+
+// Drops returns whether spurious role r is dropped in this run.
+func (p DropPolicy) Drops(r Role) bool {
+	if !p.Disable {
+		return true
+	}
+	_, ok := r.DisabledSince()
+	return ok && !p.Waiting(r)
+}
+
+// NewOwner renders owner of objects of dropped role r.
+//
+// Tries each parent of r in order, or parents revoked when disabling r.
+// Returns an empty string if ReassignTo is empty or renders no existing role.
+func (p DropPolicy) NewOwner(r Role, all Map) string {
+	if p.ReassignTo.Input == "" {
+		return ""
+	}
+	candidates := []map[string]string{{"name": r.Name}}
+	if slices.ContainsFunc(p.ReassignTo.Fields, func(f *pyfmt.Field) bool { return f.FieldName == "parent" }) {
+		candidates = nil
+		parents := r.DisabledParents()
+		for _, m := range r.Parents {
+			parents = append(parents, m.Name)
+		}
+		for _, parent := range parents {
+			candidates = append(candidates, map[string]string{"name": r.Name, "parent": parent})
+		}
+	}
+	for _, values := range candidates {
+		owner := p.ReassignTo.Format(values)
+		if _, ok := all[owner]; ok {
+			return owner
+		}
+	}
+	slog.Warn("No role to reassign objects to. Reassigning to database owner.", "role", r.Name, "reassign_to", p.ReassignTo.Input)
+	return ""
+}
+
+// Waiting returns whether r is disabled and waits for grace period.
+//
+// Such role needs no change.
+func (p DropPolicy) Waiting(r Role) bool {
+	if !p.Disable {
+		return false
+	}
+	since, ok := r.DisabledSince()
+	return ok && p.Now.Sub(since) < p.GracePeriod
+}
+
+// remove generates queries to disable or drop spurious role r.
+func (p DropPolicy) remove(r Role, all Map, fallbackOwner string) []postgres.SyncQuery {
+	if p.Drops(r) {
+		values := map[string]string{"name": r.Name, "comment": r.Comment, "id": r.ID}
+		out := p.BeforeDrop.Render(values).queries("Run before drop hook.", r.Name, false)
+		out = append(out, r.Drop(fallbackOwner, p.NewOwner(r, all))...)
+		return append(out, p.AfterDrop.Render(values).queries("Run after drop hook.", r.Name, true)...)
+	}
+	if _, ok := r.DisabledSince(); !ok {
+		return r.Disable(p.Now)
+	}
+	slog.Debug("Waiting grace period to drop disabled role.", "role", r.Name, "comment", r.Comment)
+	return nil
+}
+
+// DisabledSince returns when ldap2pg disabled role, from comment marker.
+func (r Role) DisabledSince() (time.Time, bool) {
+	m, ok := r.disabledMarker()
+	return m.since, ok
+}
+
+// DisabledParents returns parents revoked when disabling role, from comment
+// marker.
+func (r Role) DisabledParents() []string {
+	m, _ := r.disabledMarker()
+	return m.parents
+}
+
+// DisabledLogin returns whether disabling role revoked LOGIN, from comment
+// marker.
+func (r Role) DisabledLogin() bool {
+	m, _ := r.disabledMarker()
+	return m.login
+}
+
+// disabledState holds state of role before disable, from comment marker.
+type disabledState struct {
+	since   time.Time
+	login   bool
+	parents []string
+}
+
+func (r Role) disabledMarker() (m disabledState, ok bool) {
+	value, ok := strings.CutPrefix(r.Comment, disabledPrefix)
+	if !ok {
+		return
+	}
+	value, revoked, _ := strings.Cut(value, revokedSeparator)
+	value, m.login = strings.CutSuffix(value, loginMarker)
+	since, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return disabledState{}, false
+	}
+	m.since = since
+	if revoked != "" && json.Unmarshal([]byte(revoked), &m.parents) != nil {
+		slog.Warn("Bad revoked parents in disabled role comment.", "role", r.Name, "comment", r.Comment)
+		m.parents = nil
+	}
+	return m, true
+}
+
+// Disable generates queries to soft-delete role.
+//
+// Terminates sessions, prevents login, revokes parents and marks comment with
+// disable timestamp and revoked parents. Role is re-enabled by Alter if wanted
+// again.
+func (r *Role) Disable(now time.Time) (out []postgres.SyncQuery) {
+	identifier := pgx.Identifier{r.Name}
+	marker := disabledPrefix + now.UTC().Format(time.RFC3339)
+	if r.Options.CanLogin {
+		out = append(out, postgres.SyncQuery{
+			Description: "Terminate running sessions.",
+			LogArgs:     []any{"role", r.Name},
+			Database:    "<first>",
+			Query: `
+			SELECT pg_terminate_backend(pid)
+			FROM pg_catalog.pg_stat_activity
+			WHERE usename = %s;`,
+			QueryArgs: []any{r.Name},
+		}, postgres.SyncQuery{
+			Description: "Disable LOGIN.",
+			LogArgs:     []any{"role", r.Name},
+			Query:       `ALTER ROLE %s NOLOGIN;`,
+			QueryArgs:   []any{identifier},
+		})
+		// Restore LOGIN when re-enabling role.
+		marker += loginMarker
+	}
+	var revoked []string
+	for _, membership := range r.Parents {
+		revoked = append(revoked, membership.Name)
+		out = append(out, postgres.SyncQuery{
+			Description: "Revoke parent of disabled role.",
+			LogArgs: []any{
+				"role", r.Name,
+				"parent", membership.Name,
+				"grantor", membership.Grantor,
+			},
+			Query:     `REVOKE %s FROM %s GRANTED BY %s;`,
+			QueryArgs: []any{pgx.Identifier{membership.Name}, identifier, pgx.Identifier{membership.Grantor}},
+		})
+	}
+	if len(revoked) > 0 {
+		// Keep parents to render reassign_to when dropping role.
+		b, _ := json.Marshal(revoked)
+		marker += revokedSeparator + string(b)
+	}
+	out = append(out, postgres.SyncQuery{
+		Description: "Mark role as disabled.",
+		LogArgs:     []any{"role", r.Name},
+		Query:       `COMMENT ON ROLE %s IS %s;`,
+		QueryArgs:   []any{identifier, joinComment(marker, r.ID)},
+	})
+	return
+}
@@ -0,0 +1,172 @@
+package role_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/dalibo/ldap2pg/v6/internal/postgres"
+	"github.com/dalibo/ldap2pg/v6/internal/pyfmt"
+	"github.com/dalibo/ldap2pg/v6/internal/role"
+	"github.com/jackc/pgx/v5"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDisable(t *testing.T) {
+	r := require.New(t)
+
+	now := time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+	alice := role.New()
+	alice.Name = "alice"
+	alice.Options.CanLogin = true
+	alice.Parents = []role.Membership{{Name: "readers", Grantor: "postgres"}}
+
+	queries := alice.Disable(now)
+	r.Len(queries, 4)
+	r.Equal("Disable LOGIN.", queries[1].Description)
+	r.Equal("Revoke parent of disabled role.", queries[2].Description)
+	r.Equal("Mark role as disabled.", queries[3].Description)
+
+	alice.Comment = queries[3].QueryArgs[1].(string)
+	since, ok := alice.DisabledSince()
+	r.True(ok)
+	r.True(now.Equal(since))
+}
+
+func TestDropPolicy(t *testing.T) {
+	r := require.New(t)
+
+	now := time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+	policy := role.DropPolicy{Disable: true, GracePeriod: 24 * time.Hour, Now: now}
+	all := role.Map{
+		"alice": role.Role{Name: "alice", Comment: "Managed by ldap2pg"},
+		"bob":   role.Role{Name: "bob", Comment: "ldap2pg: disabled since 2025-06-01T00:00:00Z"},
+		"carol": role.Role{Name: "carol", Comment: "ldap2pg: disabled since 2025-05-01T00:00:00Z"},
+	}
+	r.False(policy.Waiting(all["alice"]))
+	r.True(policy.Waiting(all["bob"]))
+	r.False(policy.Waiting(all["carol"]))
+
+	var queries []postgres.SyncQuery
+	for q := range role.Diff(all, all, role.Map{}, "postgres", policy) {
+		queries = append(queries, q)
+	}
+	var descriptions []string
+	for _, q := range queries {
+		descriptions = append(descriptions, q.Description)
+	}
+	// alice is disabled, bob waits, carol is dropped.
+	r.Equal([]string{"Mark role as disabled.", "Drop LOGIN.", "Drop role."}, descriptions)
+}
+
+func TestDropPolicyNewOwner(t *testing.T) {
+	r := require.New(t)
+
+	all := role.Map{
+		"alice":         role.Role{Name: "alice", Parents: []role.Membership{{Name: "dba"}, {Name: "readers"}}},
+		"archive":       role.Role{Name: "archive"},
+		"readers_owner": role.Role{Name: "readers_owner"},
+	}
+
+	policy := role.DropPolicy{}
+	r.Equal("", policy.NewOwner(all["alice"], all))
+
+	policy.ReassignTo, _ = pyfmt.Parse("archive")
+	r.Equal("archive", policy.NewOwner(all["alice"], all))
+
+	policy.ReassignTo, _ = pyfmt.Parse("{parent}_owner")
+	r.Equal("readers_owner", policy.NewOwner(all["alice"], all))
+
+	policy.ReassignTo, _ = pyfmt.Parse("{name}_archive")
+	r.Equal("", policy.NewOwner(all["alice"], all))
+}
+
+func TestDropReassignTo(t *testing.T) {
+	r := require.New(t)
+
+	postgres.Databases = map[string]postgres.Database{
+		"db0": {Name: "db0", Owner: "postgres"},
+	}
+	alice := role.New()
+	alice.Name = "alice"
+
+	queries := alice.Drop("postgres", "archive")
+	r.Equal("Reassign objects and purge ACL.", queries[0].Description)
+	r.Equal("archive", queries[0].QueryArgs[1].(pgx.Identifier)[0])
+}
+
+func TestDisableThenReassignToParent(t *testing.T) {
+	r := require.New(t)
+
+	now := time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+	alice := role.New()
+	alice.Name = "alice"
+	alice.Parents = []role.Membership{{Name: "dba", Grantor: "postgres"}, {Name: "readers", Grantor: "postgres"}}
+
+	queries := alice.Disable(now)
+	mark := queries[len(queries)-1]
+	r.Equal("Mark role as disabled.", mark.Description)
+
+	// Postgres state after disable: no parents, comment marker.
+	disabled := role.New()
+	disabled.Name = "alice"
+	disabled.Comment = mark.QueryArgs[1].(string)
+	since, ok := disabled.DisabledSince()
+	r.True(ok)
+	r.True(now.Equal(since))
+	r.Equal([]string{"dba", "readers"}, disabled.DisabledParents())
+
+	all := role.Map{
+		"alice":         disabled,
+		"readers_owner": role.Role{Name: "readers_owner"},
+	}
+	policy := role.DropPolicy{Disable: true, GracePeriod: time.Hour, Now: now.Add(2 * time.Hour)}
+	policy.ReassignTo, _ = pyfmt.Parse("{parent}_owner")
+	r.True(policy.Drops(disabled))
+	r.Equal("readers_owner", policy.NewOwner(disabled, all))
+}
+
+func TestDisableThenReenable(t *testing.T) {
+	r := require.New(t)
+
+	now := time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+	alice := role.New()
+	alice.Name = "alice"
+	alice.Options.CanLogin = true
+
+	queries := alice.Disable(now)
+	mark := queries[len(queries)-1]
+	r.Equal("Mark role as disabled.", mark.Description)
+
+	// Postgres state after disable: NOLOGIN, comment marker.
+	disabled := role.New()
+	disabled.Name = "alice"
+	disabled.Comment = mark.QueryArgs[1].(string)
+	r.True(disabled.DisabledLogin())
+
+	// Rule options leave LOGIN out.
+	wanted := role.New()
+	wanted.Name = "alice"
+	wanted.Options.Managed = []string{"CREATEDB"}
+	all := role.Map{"alice": disabled}
+	var options []string
+	for q := range role.Diff(all, all, role.Map{"alice": wanted}, "postgres", role.DropPolicy{Disable: true}) {
+		if q.Description == "Alter options." {
+			options = append(options, q.Query)
+		}
+	}
+	r.Equal([]string{`ALTER ROLE %s WITH LOGIN;`}, options)
+
+	// Don't grant LOGIN to a role disabled without LOGIN.
+	group := role.New()
+	group.Name = "group"
+	disabled.Comment = group.Disable(now)[0].QueryArgs[1].(string)
+	r.False(disabled.DisabledLogin())
+	all["alice"] = disabled
+	options = nil
+	for q := range role.Diff(all, all, role.Map{"alice": wanted}, "postgres", role.DropPolicy{Disable: true}) {
+		if q.Description == "Alter options." {
+			options = append(options, q.Query)
+		}
+	}
+	r.Empty(options)
+}
@@ -0,0 +1,56 @@
+package role
+
+import (
+	"maps"
+	"slices"
+
+	"github.com/dalibo/ldap2pg/v6/internal/postgres"
+	"github.com/dalibo/ldap2pg/v6/internal/pyfmt"
+)
+
+// Hook is SQL run around a role change.
+type Hook struct {
+	SQL string
+	// Database to run SQL in. Empty means default database. __all__ means
+	// each managed database.
+	Database string
+}
+
+// queries generates queries to run hook for role name.
+//
+// after defers hook after role queries of all databases, e.g. to run
+// after_create in another database than the one creating the role.
+func (h Hook) queries(description, name string, after bool) (out []postgres.SyncQuery) {
+	if h.SQL == "" {
+		return
+	}
+	databases := []string{h.Database}
+	if h.Database == "__all__" {
+		databases = slices.Sorted(maps.Keys(postgres.Databases))
+	}
+	for _, database := range databases {
+		logArgs := []any{"role", name, "sql", h.SQL}
+		if database != "" {
+			logArgs = append(logArgs, "database", database)
+		}
+		out = append(out, postgres.SyncQuery{
+			Description: description,
+			LogArgs:     logArgs,
+			Database:    database,
+			Query:       h.SQL,
+			After:       after,
+		})
+	}
+	return
+}
+
+// HookFormat renders Hook SQL from variables.
+type HookFormat struct {
+	SQL      pyfmt.Format `mapstructure:"sql"`
+	Database string       `mapstructure:"database"`
+}
+
+// Render hook with values. values may be nil for static SQL.
+func (h HookFormat) Render(values map[string]string) Hook {
+	return Hook{SQL: h.SQL.Format(values), Database: h.Database}
+}
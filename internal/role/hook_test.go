@@ -0,0 +1,93 @@
+package role_test
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/dalibo/ldap2pg/v6/internal/postgres"
+	"github.com/dalibo/ldap2pg/v6/internal/pyfmt"
+	"github.com/dalibo/ldap2pg/v6/internal/role"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAfterAlterHook(t *testing.T) {
+	r := require.New(t)
+
+	current := role.New()
+	current.Name = "alice"
+	wanted := current
+	wanted.AfterAlter = role.Hook{SQL: "SELECT 'altered';", Database: "app"}
+	r.Empty(current.Alter(wanted))
+
+	wanted.Comment = "Developer"
+	queries := current.Alter(wanted)
+	r.Len(queries, 2)
+	r.Equal("Run after alter hook.", queries[1].Description)
+	r.Equal("app", queries[1].Database)
+}
+
+func TestDropHooks(t *testing.T) {
+	r := require.New(t)
+
+	postgres.Databases = postgres.DBMap{
+		"app":       {Name: "app", Owner: "postgres"},
+		"warehouse": {Name: "warehouse", Owner: "postgres"},
+	}
+	sql, _ := pyfmt.Parse("ALTER SCHEMA {name} RENAME TO archive_{name};")
+	policy := role.DropPolicy{
+		BeforeDrop: role.HookFormat{SQL: sql, Database: "__all__"},
+		AfterDrop:  role.HookFormat{SQL: sql},
+	}
+	all := role.Map{"alice": role.Role{Name: "alice"}}
+
+	var queries []postgres.SyncQuery
+	for q := range role.Diff(all, all, role.Map{}, "postgres", policy) {
+		queries = append(queries, q)
+	}
+	r.Equal("Run before drop hook.", queries[0].Description)
+	r.Equal("ALTER SCHEMA alice RENAME TO archive_alice;", queries[0].Query)
+	r.Equal("app", queries[0].Database)
+	r.Equal("warehouse", queries[1].Database)
+	r.Equal("Reassign objects and purge ACL.", queries[2].Description)
+	last := queries[len(queries)-1]
+	r.Equal("Run after drop hook.", last.Description)
+	r.Equal("", last.Database)
+}
+
+func TestAfterHooksOrder(t *testing.T) {
+	r := require.New(t)
+
+	postgres.Databases = postgres.DBMap{
+		"app":      {Name: "app", Owner: "postgres"},
+		"postgres": {Name: "postgres", Owner: "postgres"},
+	}
+	sql, _ := pyfmt.Parse("SELECT {name};")
+	policy := role.DropPolicy{AfterDrop: role.HookFormat{SQL: sql, Database: "app"}}
+
+	alice := role.New()
+	alice.Name = "alice"
+	alice.AfterCreate = role.Hook{SQL: "CREATE SCHEMA alice AUTHORIZATION alice;", Database: "app"}
+	bob := role.New()
+	bob.Name = "bob"
+	current := bob
+	bob.Comment = "Developer"
+	bob.AfterAlter = role.Hook{SQL: "SELECT 'altered';", Database: "app"}
+	carol := role.New()
+	carol.Name = "carol"
+
+	all := role.Map{"bob": current, "carol": carol}
+	wanted := role.Map{"alice": alice, "bob": bob}
+	var descriptions []string
+	for q := range postgres.GroupByDatabase("postgres", role.Diff(all, all, wanted, "postgres", policy)) {
+		descriptions = append(descriptions, q.Database+": "+q.Description)
+	}
+
+	index := func(s string) int {
+		i := slices.Index(descriptions, s)
+		r.NotEqual(-1, i, s)
+		return i
+	}
+	r.Less(index("postgres: Create role."), index("app: Run after create hook."))
+	r.Less(index("postgres: Set role comment."), index("app: Run after alter hook."))
+	r.Less(index("postgres: Drop role."), index("app: Run after drop hook."))
+}
@@ -0,0 +1,90 @@
+package role
+
+import (
+	"encoding/hex"
+	"log/slog"
+	"regexp"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// idRe matches stable id suffix in role comment.
+var idRe = regexp.MustCompile(` \[ldap2pg-id:([^\]]+)\]$`)
+
+// splitComment separates stable id suffix from role comment.
+func splitComment(comment string) (text, id string) {
+	m := idRe.FindStringSubmatchIndex(comment)
+	if m == nil {
+		return comment, ""
+	}
+	return comment[:m[0]], comment[m[2]:m[3]]
+}
+
+// joinComment appends stable id suffix to role comment.
+func joinComment(text, id string) string {
+	if id == "" {
+		return text
+	}
+	return text + " [ldap2pg-id:" + id + "]"
+}
+
+// SanitizeID returns id suitable for comment suffix.
+//
+// Hex encodes binary values like Active Directory objectGUID.
+func SanitizeID(id string) string {
+	if !utf8.ValidString(id) || strings.ContainsFunc(id, func(r rune) bool {
+		return !unicode.IsPrint(r) || r == ']'
+	}) {
+		return hex.EncodeToString([]byte(id))
+	}
+	return id
+}
+
+// Renames returns new name of current roles, indexed by current name.
+//
+// Matches by stable id managed roles not wanted anymore with wanted roles
+// missing in Postgres. Ignores ambiguous ids.
+func Renames(all, managed, wanted Map) map[string]string {
+	current := map[string]string{}
+	ambiguous := map[string]bool{}
+	for name, r := range managed {
+		if r.ID == "" {
+			continue
+		}
+		if _, ok := wanted[name]; ok {
+			continue
+		}
+		if _, ok := all[name]; !ok {
+			continue
+		}
+		if _, ok := current[r.ID]; ok {
+			ambiguous[r.ID] = true
+		}
+		current[r.ID] = name
+	}
+
+	renames := map[string]string{}
+	targets := map[string]string{}
+	for name, r := range wanted {
+		if r.ID == "" {
+			continue
+		}
+		if _, ok := all[name]; ok {
+			continue
+		}
+		old, ok := current[r.ID]
+		if !ok {
+			continue
+		}
+		if _, ok := targets[r.ID]; ok || ambiguous[r.ID] {
+			slog.Warn("Ambiguous role id. Not renaming.", "id", r.ID, "role", old)
+			ambiguous[r.ID] = true
+			delete(renames, old)
+			continue
+		}
+		targets[r.ID] = name
+		renames[old] = name
+	}
+	return renames
+}
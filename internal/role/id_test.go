@@ -0,0 +1,70 @@
+package role_test
+
+import (
+	"testing"
+
+	"github.com/dalibo/ldap2pg/v6/internal/postgres"
+	"github.com/dalibo/ldap2pg/v6/internal/role"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSanitizeID(t *testing.T) {
+	r := require.New(t)
+
+	r.Equal("6e1ab4f2-7c5c-4d5b-9a5e-1b2c3d4e5f60", role.SanitizeID("6e1ab4f2-7c5c-4d5b-9a5e-1b2c3d4e5f60"))
+	r.Equal("00ff5d", role.SanitizeID("\x00\xff]"))
+}
+
+func TestRename(t *testing.T) {
+	r := require.New(t)
+
+	all := role.Map{
+		"jdoe":  role.Role{Name: "jdoe", Comment: "Managed by ldap2pg", ID: "1234"},
+		"alice": role.Role{Name: "alice", Comment: "Managed by ldap2pg", ID: "5678"},
+	}
+	wanted := role.Map{
+		"jsmith": role.Role{Name: "jsmith", Comment: "Managed by ldap2pg", ID: "1234"},
+	}
+
+	r.Equal(map[string]string{"jdoe": "jsmith"}, role.Renames(all, all, wanted))
+	r.Equal([]string{"alice"}, role.Spurious(all, all, wanted))
+
+	var queries []postgres.SyncQuery
+	for q := range role.Diff(all, all, wanted, "postgres", role.DropPolicy{}) {
+		queries = append(queries, q)
+	}
+	r.Equal("Rename role.", queries[0].Description)
+	r.Equal(`ALTER ROLE %s RENAME TO %s;`, queries[0].Query)
+	for _, q := range queries {
+		r.NotEqual("Create role.", q.Description)
+	}
+}
+
+func TestAmbiguousID(t *testing.T) {
+	r := require.New(t)
+
+	all := role.Map{
+		"jdoe": role.Role{Name: "jdoe", ID: "1234"},
+	}
+	wanted := role.Map{
+		"jsmith":  role.Role{Name: "jsmith", ID: "1234"},
+		"jsmith2": role.Role{Name: "jsmith2", ID: "1234"},
+	}
+	r.Empty(role.Renames(all, all, wanted))
+}
+
+func TestCommentID(t *testing.T) {
+	r := require.New(t)
+
+	current := role.Role{Name: "alice", Comment: "Managed by ldap2pg"}
+	wanted := current
+	wanted.ID = "1234"
+	queries := current.Alter(wanted)
+	r.Len(queries, 1)
+	r.Equal("Managed by ldap2pg [ldap2pg-id:1234]", queries[0].QueryArgs[1])
+
+	// Rule without id keeps current id.
+	current.ID = "1234"
+	wanted.ID = ""
+	r.Empty(current.Alter(wanted))
+}
@@ -1,14 +1,80 @@
 package role
 
+import (
+	"strings"
+)
+
+// Membership of a role in a parent role.
+//
+// Admin, Inherit and Set are nil when unspecified. Inherit and Set requires
+// Postgres 16.
 type Membership struct {
 	Grantor string
 	Name    string
+	Admin   *bool
+	Inherit *bool
+	Set     *bool
 }
 
 func (m Membership) String() string {
 	return m.Name
 }
 
+// HasOptions returns whether m requires options on GRANT.
+func (m Membership) HasOptions() bool {
+	return m.Admin != nil && *m.Admin || m.Inherit != nil || m.Set != nil
+}
+
+// GrantOptions returns WITH clause to grant a new membership.
+//
+// Returns an empty string if m has no options.
+func (m Membership) GrantOptions() string {
+	var options []string
+	if m.Admin != nil && *m.Admin {
+		options = append(options, "ADMIN OPTION")
+	}
+	if m.Inherit != nil {
+		options = append(options, "INHERIT "+sqlBool(*m.Inherit))
+	}
+	if m.Set != nil {
+		options = append(options, "SET "+sqlBool(*m.Set))
+	}
+	if len(options) == 0 {
+		return ""
+	}
+	return " WITH " + strings.Join(options, ", ")
+}
+
+// AlterOptions returns membership to grant on current membership to match
+// wanted options.
+//
+// Options unspecified in wanted or not inspected in current, like inherit
+// before Postgres 16, are ignored. revokeAdmin tells whether to revoke admin
+// option.
+func (m Membership) AlterOptions(current Membership) (grant Membership, revokeAdmin bool) {
+	if m.Admin != nil && current.Admin != nil && *m.Admin != *current.Admin {
+		if *m.Admin {
+			grant.Admin = m.Admin
+		} else {
+			revokeAdmin = true
+		}
+	}
+	if m.Inherit != nil && current.Inherit != nil && *m.Inherit != *current.Inherit {
+		grant.Inherit = m.Inherit
+	}
+	if m.Set != nil && current.Set != nil && *m.Set != *current.Set {
+		grant.Set = m.Set
+	}
+	return
+}
+
+func sqlBool(b bool) string {
+	if b {
+		return "TRUE"
+	}
+	return "FALSE"
+}
+
 func (r Role) MemberOf(p string) bool {
 	for _, m := range r.Parents {
 		if p == m.Name {
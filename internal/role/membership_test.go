@@ -42,3 +42,43 @@ func TestLoop(t *testing.T) {
 	err := m.Check()
 	r.Error(err)
 }
+
+func TestMembershipOptions(t *testing.T) {
+	r := require.New(t)
+
+	yes, no := true, false
+	current := role.Role{
+		Name: "toto",
+		Parents: []role.Membership{
+			{Name: "owners", Grantor: "postgres", Admin: &yes, Inherit: &yes, Set: &yes},
+			{Name: "readers", Grantor: "postgres", Admin: &no, Inherit: &yes, Set: &yes},
+		},
+	}
+	wanted := role.Role{
+		Name: "toto",
+		Parents: []role.Membership{
+			{Name: "owners", Admin: &no, Inherit: &no},
+			{Name: "readers"},
+			{Name: "writers", Set: &no},
+		},
+	}
+
+	queries := current.Alter(wanted)
+	r.Len(queries, 3)
+	r.Equal("Grant missing parent.", queries[0].Description)
+	r.Equal(`GRANT %s TO %s WITH SET FALSE;`, queries[0].Query)
+	r.Equal("Alter membership options.", queries[1].Description)
+	r.Equal(`GRANT %s TO %s WITH INHERIT FALSE GRANTED BY %s;`, queries[1].Query)
+	r.Equal("Revoke membership admin option.", queries[2].Description)
+}
+
+func TestMembershipOptionsPre16(t *testing.T) {
+	r := require.New(t)
+
+	no := false
+	current := role.Membership{Name: "owners", Admin: &no}
+	wanted := role.Membership{Name: "owners", Inherit: &no}
+	grant, revokeAdmin := wanted.AlterOptions(current)
+	r.False(revokeAdmin)
+	r.Equal("", grant.GrantOptions())
+}
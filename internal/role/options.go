@@ -5,6 +5,7 @@ import (
 	"log/slog"
 	"reflect"
 	"slices"
+	"strconv"
 	"strings"
 )
 
@@ -17,6 +18,48 @@ type Options struct {
 	Replication bool `column:"rolreplication" mapstructure:"REPLICATION"`
 	ByPassRLS   bool `column:"rolbypassrls" mapstructure:"BYPASSRLS"`
 	ConnLimit   int  `column:"rolconnlimit" mapstructure:"CONNECTION LIMIT"`
+	// Managed lists SQL tokens of options synchronized by Diff. nil means
+	// all options.
+	Managed []string `mapstructure:"-"`
+}
+
+// IsManaged returns whether Diff synchronizes option token.
+func (o Options) IsManaged(token string) bool {
+	return o.Managed == nil || slices.Contains(o.Managed, token)
+}
+
+// Manage adds tokens to managed options.
+func (o *Options) Manage(tokens ...string) {
+	if o.Managed == nil {
+		return
+	}
+	for _, token := range tokens {
+		if !slices.Contains(o.Managed, token) {
+			// Clip to never share appended token with other roles.
+			o.Managed = append(slices.Clip(o.Managed), token)
+		}
+	}
+}
+
+// field returns addressable option field by SQL token.
+func (o *Options) field(token string) reflect.Value {
+	v := reflect.ValueOf(o).Elem()
+	for _, f := range reflect.VisibleFields(v.Type()) {
+		if f.Tag.Get("mapstructure") == token {
+			return v.FieldByIndex(f.Index)
+		}
+	}
+	panic("unknown option: " + token)
+}
+
+// optionTokens lists SQL tokens of all options.
+func optionTokens() (tokens []string) {
+	for _, f := range reflect.VisibleFields(reflect.TypeOf(Options{})) {
+		if f.Tag.Get("column") != "" {
+			tokens = append(tokens, f.Tag.Get("mapstructure"))
+		}
+	}
+	return
 }
 
 func (o Options) String() string {
@@ -42,6 +85,8 @@ func (o Options) String() string {
 }
 
 // Diff returns the SQL to match wanted role options
+//
+// Ignores options not managed by wanted.
 func (o Options) Diff(wanted Options) string {
 	v := reflect.ValueOf(o)
 	wantedV := reflect.ValueOf(wanted)
@@ -50,6 +95,9 @@ func (o Options) Diff(wanted Options) string {
 		if !isColumnEnabled(f.Tag.Get("column")) {
 			continue
 		}
+		if !wanted.IsManaged(f.Tag.Get("mapstructure")) {
+			continue
+		}
 		fv := v.FieldByName(f.Name)
 		wantedFV := wantedV.FieldByName(f.Name)
 		switch f.Type.Kind() {
@@ -73,6 +121,32 @@ func (o Options) Diff(wanted Options) string {
 	return b.String()
 }
 
+// Set option by SQL token from a string value.
+func (o *Options) Set(token, value string) error {
+	v := reflect.ValueOf(o).Elem()
+	for _, f := range reflect.VisibleFields(v.Type()) {
+		if f.Tag.Get("mapstructure") != token {
+			continue
+		}
+		switch f.Type.Kind() {
+		case reflect.Bool:
+			b, err := strconv.ParseBool(value)
+			if err != nil {
+				return fmt.Errorf("%s: bad boolean: %s", token, value)
+			}
+			v.FieldByName(f.Name).SetBool(b)
+		case reflect.Int:
+			i, err := strconv.Atoi(value)
+			if err != nil {
+				return fmt.Errorf("%s: bad integer: %s", token, value)
+			}
+			v.FieldByName(f.Name).SetInt(int64(i))
+		}
+		return nil
+	}
+	return fmt.Errorf("unknown option: %s", token)
+}
+
 func (o *Options) LoadRow(row []any) {
 	for i, value := range row {
 		colName := getColumnNameByOrder(i)
@@ -112,6 +186,9 @@ func ProcessColumns(columns []string, super bool) []string {
 	t := reflect.TypeOf(Options{})
 	for _, f := range reflect.VisibleFields(t) {
 		name := f.Tag.Get("column")
+		if name == "" {
+			continue
+		}
 		knownColumns = append(knownColumns, name)
 		instanceColumns.availability[name] = false
 	}
@@ -25,6 +25,28 @@ func TestOptionsDiff(t *testing.T) {
 	r.Equal("NOSUPERUSER CONNECTION LIMIT -1", diff)
 }
 
+func TestOptionsDiffManaged(t *testing.T) {
+	r := require.New(t)
+
+	ProcessColumns([]string{
+		"rolsuper",
+		"rolinherit",
+		"rolreplication",
+		"rolconnlimit",
+		"rolcanlogin",
+	}, true)
+
+	current := Options{Replication: true, ConnLimit: 5}
+	wanted := Options{CanLogin: true, ConnLimit: -1, Managed: []string{"LOGIN"}}
+	r.Equal("LOGIN", current.Diff(wanted))
+
+	wanted.Manage("CONNECTION LIMIT")
+	r.Equal("LOGIN CONNECTION LIMIT -1", current.Diff(wanted))
+
+	wanted.Managed = []string{}
+	r.Equal("", current.Diff(wanted))
+}
+
 func TestUnhandledOptions(t *testing.T) {
 	r := require.New(t)
 
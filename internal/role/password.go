@@ -0,0 +1,90 @@
+package role
+
+import (
+	"log/slog"
+	"strings"
+	"sync"
+
+	"github.com/dalibo/ldap2pg/v6/internal/postgres"
+	"github.com/jackc/pgx/v5"
+)
+
+// Password of a role.
+//
+// Hash is never logged.
+type Password struct {
+	// Policy is on_create, always or null. Empty means password is not
+	// managed.
+	Policy string
+	// Hash is a SCRAM-SHA-256 verifier. Empty means no password.
+	Hash string
+	// Inspected is true if Hash has been read from pg_authid. Requires
+	// superuser.
+	Inspected bool
+}
+
+// IsVerifier returns whether s is a SCRAM-SHA-256 verifier.
+func IsVerifier(s string) bool {
+	return strings.HasPrefix(s, "SCRAM-SHA-256$")
+}
+
+func (p Password) String() string {
+	if p.Hash == "" {
+		return "NULL"
+	}
+	return "********"
+}
+
+// create generates query to set password of a new role.
+func (p Password) create(name string) (out []postgres.SyncQuery) {
+	if p.Hash == "" || p.Policy == "null" || p.Policy == "" {
+		return
+	}
+	out = append(out, postgres.SyncQuery{
+		Description: "Set role password.",
+		LogArgs:     []any{"role", name},
+		Query:       `ALTER ROLE %s WITH PASSWORD %s;`,
+		QueryArgs:   []any{pgx.Identifier{name}, postgres.Secret(p.Hash)},
+	})
+	return
+}
+
+// uninspectedWarning logs once that always and null policies are ignored.
+var uninspectedWarning sync.Once
+
+// alter generates query to update current password to match p.
+//
+// Requires inspected current password.
+func (p Password) alter(name string, current Password) (out []postgres.SyncQuery) {
+	if !current.Inspected {
+		if p.Policy == "always" || p.Policy == "null" {
+			uninspectedWarning.Do(func() {
+				slog.Warn("Can't inspect passwords without superuser. Ignoring password policy of existing roles.", "role", name, "policy", p.Policy)
+			})
+		}
+		return
+	}
+	switch p.Policy {
+	case "always":
+		if p.Hash == "" || p.Hash == current.Hash {
+			return
+		}
+		out = append(out, postgres.SyncQuery{
+			Description: "Update role password.",
+			LogArgs:     []any{"role", name},
+			Query:       `ALTER ROLE %s WITH PASSWORD %s;`,
+			QueryArgs:   []any{pgx.Identifier{name}, postgres.Secret(p.Hash)},
+		})
+	case "null":
+		if current.Hash == "" {
+			return
+		}
+		out = append(out, postgres.SyncQuery{
+			Description: "Remove role password.",
+			LogArgs:     []any{"role", name},
+			Query:       `ALTER ROLE %s WITH PASSWORD NULL;`,
+			QueryArgs:   []any{pgx.Identifier{name}},
+		})
+	}
+	return
+}
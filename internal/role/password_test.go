@@ -0,0 +1,65 @@
+package role_test
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/dalibo/ldap2pg/v6/internal/role"
+	"github.com/stretchr/testify/require"
+)
+
+const verifier = "SCRAM-SHA-256$4096:c2FsdA==$c3RvcmVk:c2VydmVy"
+
+func TestPasswordCreate(t *testing.T) {
+	r := require.New(t)
+
+	alice := role.New()
+	alice.Name = "alice"
+	alice.Password = role.Password{Policy: "on_create", Hash: verifier}
+	queries := alice.Create()
+	var found bool
+	for _, q := range queries {
+		if q.Description != "Set role password." {
+			continue
+		}
+		found = true
+		r.True(q.HasSecret())
+		r.NotContains(fmt.Sprint(q.QueryArgs...), verifier)
+		r.NotContains(fmt.Sprint(q.LogArgs...), verifier)
+	}
+	r.True(found)
+
+	alice.Password = role.Password{Policy: "null"}
+	for _, q := range alice.Create() {
+		r.NotEqual("Set role password.", q.Description)
+	}
+}
+
+func TestPasswordAlter(t *testing.T) {
+	r := require.New(t)
+
+	current := role.New()
+	current.Name = "alice"
+	current.Password = role.Password{Hash: "SCRAM-SHA-256$old", Inspected: true}
+
+	wanted := current
+	wanted.Password = role.Password{Policy: "on_create", Hash: verifier}
+	r.Empty(current.Alter(wanted))
+
+	wanted.Password = role.Password{Policy: "always", Hash: verifier}
+	queries := current.Alter(wanted)
+	r.Len(queries, 1)
+	r.Equal("Update role password.", queries[0].Description)
+	r.True(queries[0].HasSecret())
+
+	wanted.Password = role.Password{Policy: "null"}
+	queries = current.Alter(wanted)
+	r.Len(queries, 1)
+	r.Equal(`ALTER ROLE %s WITH PASSWORD NULL;`, queries[0].Query)
+
+	// Can't compare without superuser.
+	current.Password = role.Password{}
+	r.Empty(current.Alter(wanted))
+	wanted.Password = role.Password{Policy: "always", Hash: verifier}
+	r.Empty(current.Alter(wanted))
+}
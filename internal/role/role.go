@@ -4,21 +4,34 @@ import (
 	"fmt"
 	"maps"
 	"slices"
+	"strings"
+	"time"
 
 	"github.com/dalibo/ldap2pg/v6/internal/postgres"
-	mapset "github.com/deckarep/golang-set/v2"
 	"github.com/go-viper/mapstructure/v2"
 	"github.com/jackc/pgx/v5"
 )
 
 type Role struct {
-	Name         string
-	Comment      string
-	Parents      []Membership
-	Options      Options
-	Config       Config
-	BeforeCreate string
-	AfterCreate  string
+	Name    string
+	Comment string
+	// ID is a stable identifier from directory, stored as comment suffix.
+	ID      string
+	Parents []Membership
+	Options Options
+	Config  Config
+	// DatabaseConfig is config of role in each database, indexed by database
+	// name or __all__. nil means unmanaged.
+	DatabaseConfig map[string]Config
+	// SecurityLabels nil means unmanaged.
+	SecurityLabels SecurityLabels
+	Password       Password
+	// ValidUntil is the expiration of role. Zero time means infinity. nil
+	// means unmanaged.
+	ValidUntil   *time.Time
+	BeforeCreate Hook
+	AfterCreate  Hook
+	AfterAlter   Hook
 }
 
 func New() Role {
@@ -31,11 +44,17 @@ func RowTo(row pgx.CollectableRow) (r Role, err error) {
 	var variableRow any
 	var parents []any // jsonb
 	var config []string
+	var databaseConfig map[string][]string // jsonb
 	r = New()
-	err = row.Scan(&r.Name, &variableRow, &r.Comment, &parents, &config)
+	err = row.Scan(&r.Name, &variableRow, &r.Comment, &parents, &config, &r.ValidUntil, &databaseConfig, &r.SecurityLabels)
 	if err != nil {
 		return
 	}
+	if r.ValidUntil == nil {
+		// NULL or infinity.
+		r.ValidUntil = &time.Time{}
+	}
+	r.Comment, r.ID = splitComment(r.Comment)
 	for _, jsonb := range parents {
 		if jsonb == nil {
 			continue
@@ -49,6 +68,15 @@ func RowTo(row pgx.CollectableRow) (r Role, err error) {
 	}
 	r.Options.LoadRow(variableRow.([]any))
 	r.Config.Parse(config)
+	r.DatabaseConfig = make(map[string]Config)
+	for database, rows := range databaseConfig {
+		c := make(Config)
+		c.Parse(rows)
+		r.DatabaseConfig[database] = c
+	}
+	if r.SecurityLabels == nil {
+		r.SecurityLabels = make(SecurityLabels)
+	}
 	return
 }
 
@@ -72,6 +100,14 @@ func (r Role) Check(m Map, genealogy []string) error {
 	return nil
 }
 
+// ValidUntilString formats role expiration as a Postgres timestamp.
+func ValidUntilString(t *time.Time) string {
+	if t == nil || t.IsZero() {
+		return "infinity"
+	}
+	return t.UTC().Format(time.RFC3339)
+}
+
 func (r *Role) String() string {
 	return r.Name
 }
@@ -98,22 +134,31 @@ func (r *Role) Alter(wanted Role) (out []postgres.SyncQuery) {
 		})
 	}
 
-	missingMemberships := r.MissingParents(wanted.Parents)
-	if len(missingMemberships) > 0 {
-		var parentIdentifiers []any
-		for _, membership := range missingMemberships {
-			parentIdentifiers = append(parentIdentifiers, pgx.Identifier{membership.Name})
-		}
+	out = append(out, wanted.Password.alter(r.Name, r.Password)...)
+
+	if wanted.ValidUntil != nil && (r.ValidUntil == nil || !r.ValidUntil.Equal(*wanted.ValidUntil)) {
 		out = append(out, postgres.SyncQuery{
-			Description: "Grant missing parents.",
+			Description: "Set role expiration.",
 			LogArgs: []any{
 				"role", r.Name,
-				"parents", missingMemberships,
+				"current", ValidUntilString(r.ValidUntil),
+				"wanted", ValidUntilString(wanted.ValidUntil),
 			},
-			Query:     `GRANT %s TO %s;`,
-			QueryArgs: []any{parentIdentifiers, identifier},
+			Query:     `ALTER ROLE %s VALID UNTIL %s;`,
+			QueryArgs: []any{identifier, ValidUntilString(wanted.ValidUntil)},
 		})
 	}
+
+	missingMemberships := r.MissingParents(wanted.Parents)
+	out = append(out, r.grantParents(missingMemberships)...)
+	for _, membership := range wanted.Parents {
+		for _, current := range r.Parents {
+			if current.Name != membership.Name {
+				continue
+			}
+			out = append(out, r.alterMembership(current, membership)...)
+		}
+	}
 	spuriousMemberships := wanted.MissingParents(r.Parents)
 	for _, membership := range spuriousMemberships {
 		out = append(out, postgres.SyncQuery{
@@ -136,113 +181,98 @@ func (r *Role) Alter(wanted Role) (out []postgres.SyncQuery) {
 		})
 	}
 
-	if wanted.Comment != r.Comment {
+	wantedID := wanted.ID
+	if wantedID == "" {
+		// Keep id of unconfigured rule.
+		wantedID = r.ID
+	}
+	if wanted.Comment != r.Comment || wantedID != r.ID {
 		out = append(out, postgres.SyncQuery{
 			Description: "Set role comment.",
 			LogArgs: []any{
 				"role", r.Name,
-				"current", r.Comment,
-				"wanted", wanted.Comment,
+				"current", joinComment(r.Comment, r.ID),
+				"wanted", joinComment(wanted.Comment, wantedID),
 			},
 			Query:     `COMMENT ON ROLE %s IS %s;`,
-			QueryArgs: []any{identifier, wanted.Comment},
+			QueryArgs: []any{identifier, joinComment(wanted.Comment, wantedID)},
 		})
 	}
 
 	if wanted.Config != nil {
-		currentKeys := mapset.NewSetFromMapKeys(r.Config)
-		wantedKeys := mapset.NewSetFromMapKeys(wanted.Config)
-		missingKeys := wantedKeys.Clone()
-		for k := range currentKeys.Iter() {
-			if !wantedKeys.Contains(k) {
-				out = append(out, postgres.SyncQuery{
-					Description: "Reset role config.",
-					LogArgs: []any{
-						"role", r.Name,
-						"config", k,
-					},
-					Query:     `ALTER ROLE %s RESET %s;`,
-					QueryArgs: []any{identifier, pgx.Identifier{k}},
-				})
-				continue
-			}
-
-			missingKeys.Remove(k)
-
-			currentValue := r.Config[k]
-			wantedValue := wanted.Config[k]
-			if wantedValue == currentValue {
-				continue
+		out = append(out, r.Config.alter(r.Name, "", wanted.Config)...)
+	}
+	if wanted.SecurityLabels != nil {
+		out = append(out, r.SecurityLabels.alter(r.Name, wanted.SecurityLabels)...)
+	}
+	if wanted.DatabaseConfig != nil {
+		currentConfig := r.databaseConfig()
+		wantedConfig := wanted.databaseConfig()
+		for _, database := range slices.Sorted(maps.Keys(postgres.Databases)) {
+			current := currentConfig[database]
+			if current == nil {
+				current = Config{}
 			}
-			out = append(out, postgres.SyncQuery{
-				Description: "Update role config.",
-				LogArgs: []any{
-					"role", r.Name,
-					"config", k,
-					"current", currentValue,
-					"wanted", wantedValue,
-				},
-				Query:     `ALTER ROLE %s SET %s TO %s;`,
-				QueryArgs: []any{identifier, pgx.Identifier{k}, wantedValue},
-			})
-		}
-
-		for k := range missingKeys.Iter() {
-			v := wanted.Config[k]
-			out = append(out, postgres.SyncQuery{
-				Description: "Set role config.",
-				LogArgs: []any{
-					"role", r.Name,
-					"config", k,
-					"value", v,
-				},
-				Query:     `ALTER ROLE %s SET %s TO %s;`,
-				QueryArgs: []any{identifier, pgx.Identifier{k}, v},
-			})
+			out = append(out, current.alter(r.Name, database, wantedConfig[database])...)
 		}
 	}
 
+	if len(out) > 0 {
+		out = append(out, wanted.AfterAlter.queries("Run after alter hook.", r.Name, true)...)
+	}
 	return
 }
 
 func (r *Role) Create() (out []postgres.SyncQuery) {
 	identifier := pgx.Identifier{r.Name}
 
-	if r.BeforeCreate != "" {
-		out = append(out, postgres.SyncQuery{
-			Description: "Run before create hook.",
-			LogArgs:     []any{"role", r.Name, "sql", r.BeforeCreate},
-			Query:       r.BeforeCreate,
-		})
+	out = append(out, r.BeforeCreate.queries("Run before create hook.", r.Name, false)...)
+
+	validUntil := ""
+	var validUntilArgs []any
+	if r.ValidUntil != nil && !r.ValidUntil.IsZero() {
+		validUntil = " VALID UNTIL %s"
+		validUntilArgs = append(validUntilArgs, ValidUntilString(r.ValidUntil))
 	}
 
-	if len(r.Parents) > 0 {
+	// Memberships with options are granted after creation.
+	var plainParents, optionParents []Membership
+	for _, parent := range r.Parents {
+		if parent.HasOptions() {
+			optionParents = append(optionParents, parent)
+		} else {
+			plainParents = append(plainParents, parent)
+		}
+	}
+	if len(plainParents) > 0 {
 		parents := []any{}
-		for _, parent := range r.Parents {
+		for _, parent := range plainParents {
 			parents = append(parents, pgx.Identifier{parent.Name})
 		}
 		out = append(out, postgres.SyncQuery{
 			Description: "Create role.",
-			LogArgs:     []any{"role", r.Name, "parents", r.Parents},
+			LogArgs:     []any{"role", r.Name, "parents", plainParents},
 			Query: `
 			CREATE ROLE %s
-			WITH ` + r.Options.String() + `
+			WITH ` + r.Options.String() + validUntil + `
 			IN ROLE %s;`,
-			QueryArgs: []any{identifier, parents},
+			QueryArgs: append(append([]any{identifier}, validUntilArgs...), parents),
 		})
 	} else {
 		out = append(out, postgres.SyncQuery{
 			Description: "Create role.",
 			LogArgs:     []any{"role", r.Name},
-			Query:       `CREATE ROLE %s WITH ` + r.Options.String() + `;`,
-			QueryArgs:   []any{identifier},
+			Query:       `CREATE ROLE %s WITH ` + r.Options.String() + validUntil + `;`,
+			QueryArgs:   append([]any{identifier}, validUntilArgs...),
 		})
 	}
+	out = append(out, r.Password.create(r.Name)...)
+	out = append(out, r.grantParents(optionParents)...)
 	out = append(out, postgres.SyncQuery{
 		Description: "Set role comment.",
 		LogArgs:     []any{"role", r.Name},
 		Query:       `COMMENT ON ROLE %s IS %s;`,
-		QueryArgs:   []any{identifier, r.Comment},
+		QueryArgs:   []any{identifier, joinComment(r.Comment, r.ID)},
 	})
 
 	if r.Config != nil {
@@ -255,19 +285,111 @@ func (r *Role) Create() (out []postgres.SyncQuery) {
 			})
 		}
 	}
+	out = append(out, SecurityLabels{}.alter(r.Name, r.SecurityLabels)...)
+
+	databaseConfig := r.databaseConfig()
+	for _, database := range slices.Sorted(maps.Keys(databaseConfig)) {
+		out = append(out, Config{}.alter(r.Name, database, databaseConfig[database])...)
+	}
+
+	out = append(out, r.AfterCreate.queries("Run after create hook.", r.Name, true)...)
+
+	return
+}
 
-	if r.AfterCreate != "" {
+// grantParents generates GRANT queries for new memberships.
+//
+// Memberships without options are granted in a single query.
+func (r *Role) grantParents(parents []Membership) (out []postgres.SyncQuery) {
+	identifier := pgx.Identifier{r.Name}
+	var plain, withOptions []Membership
+	var plainIdentifiers []any
+	for _, membership := range parents {
+		if membership.HasOptions() {
+			withOptions = append(withOptions, membership)
+			continue
+		}
+		plain = append(plain, membership)
+		plainIdentifiers = append(plainIdentifiers, pgx.Identifier{membership.Name})
+	}
+	if len(plain) > 0 {
+		out = append(out, postgres.SyncQuery{
+			Description: "Grant missing parents.",
+			LogArgs: []any{
+				"role", r.Name,
+				"parents", plain,
+			},
+			Query:     `GRANT %s TO %s;`,
+			QueryArgs: []any{plainIdentifiers, identifier},
+		})
+	}
+	for _, membership := range withOptions {
+		options := membership.GrantOptions()
 		out = append(out, postgres.SyncQuery{
-			Description: "Run after create hook.",
-			LogArgs:     []any{"role", r.Name, "sql", r.AfterCreate},
-			Query:       r.AfterCreate,
+			Description: "Grant missing parent.",
+			LogArgs: []any{
+				"role", r.Name,
+				"parent", membership.Name,
+				"options", strings.TrimPrefix(options, " WITH "),
+			},
+			Query:     `GRANT %s TO %s` + options + `;`,
+			QueryArgs: []any{pgx.Identifier{membership.Name}, identifier},
 		})
 	}
+	return
+}
 
+// alterMembership generates queries to update options of current membership.
+//
+// Set grantor explicitly to update existing membership rather than adding a
+// new one.
+func (r *Role) alterMembership(current, wanted Membership) (out []postgres.SyncQuery) {
+	identifier := pgx.Identifier{r.Name}
+	grant, revokeAdmin := wanted.AlterOptions(current)
+	if options := grant.GrantOptions(); options != "" {
+		out = append(out, postgres.SyncQuery{
+			Description: "Alter membership options.",
+			LogArgs: []any{
+				"role", r.Name,
+				"parent", current.Name,
+				"grantor", current.Grantor,
+				"options", strings.TrimPrefix(options, " WITH "),
+			},
+			Query:     `GRANT %s TO %s` + options + ` GRANTED BY %s;`,
+			QueryArgs: []any{pgx.Identifier{current.Name}, identifier, pgx.Identifier{current.Grantor}},
+		})
+	}
+	if revokeAdmin {
+		out = append(out, postgres.SyncQuery{
+			Description: "Revoke membership admin option.",
+			LogArgs: []any{
+				"role", r.Name,
+				"parent", current.Name,
+				"grantor", current.Grantor,
+			},
+			Query:     `REVOKE ADMIN OPTION FOR %s FROM %s GRANTED BY %s;`,
+			QueryArgs: []any{pgx.Identifier{current.Name}, identifier, pgx.Identifier{current.Grantor}},
+		})
+	}
 	return
 }
 
-func (r *Role) Drop(fallbackOwner string) (out []postgres.SyncQuery) {
+// Rename generates query to rename role, keeping ownerships and privileges.
+func (r *Role) Rename(name string) []postgres.SyncQuery {
+	return []postgres.SyncQuery{{
+		Description: "Rename role.",
+		LogArgs:     []any{"role", r.Name, "name", name, "id", r.ID},
+		Query:       `ALTER ROLE %s RENAME TO %s;`,
+		QueryArgs:   []any{pgx.Identifier{r.Name}, pgx.Identifier{name}},
+	}}
+}
+
+// Drop generates queries to drop role.
+//
+// Reassigns databases owned by role to fallbackOwner and other objects to
+// database owner. If newOwner is not empty, reassigns databases and objects
+// to newOwner.
+func (r *Role) Drop(fallbackOwner, newOwner string) (out []postgres.SyncQuery) {
 	identifier := pgx.Identifier{r.Name}
 	if r.Options.CanLogin {
 		out = append(out, postgres.SyncQuery{
@@ -282,6 +404,9 @@ func (r *Role) Drop(fallbackOwner string) (out []postgres.SyncQuery) {
 		})
 	}
 
+	if newOwner != "" {
+		fallbackOwner = newOwner
+	}
 	for dbname, database := range postgres.Databases {
 		if database.Owner == r.Name {
 			out = append(out, postgres.SyncQuery{
@@ -301,17 +426,21 @@ func (r *Role) Drop(fallbackOwner string) (out []postgres.SyncQuery) {
 			database.Owner = fallbackOwner
 			postgres.Databases[dbname] = database
 		}
+		owner := database.Owner
+		if newOwner != "" {
+			owner = newOwner
+		}
 		out = append(out, postgres.SyncQuery{
 			Description: "Reassign objects and purge ACL.",
 			LogArgs: []any{
-				"role", r.Name, "owner", database.Owner,
+				"role", r.Name, "owner", owner,
 			},
 			Database: database.Name,
 			Query: `
 			REASSIGN OWNED BY %s TO %s;
 			DROP OWNED BY %s;`,
 			QueryArgs: []any{
-				identifier, pgx.Identifier{database.Owner}, identifier,
+				identifier, pgx.Identifier{owner}, identifier,
 			},
 		})
 	}
@@ -329,16 +458,62 @@ func (r *Role) Drop(fallbackOwner string) (out []postgres.SyncQuery) {
 	return
 }
 
+// Merge unions parents, config and labels of o in r.
+//
+// Keeps attributes of r defined in both roles. See Conflicts.
 func (r *Role) Merge(o Role) {
+	r.mergeOptions(o)
 	for _, membership := range o.Parents {
 		if r.MemberOf(membership.Name) {
 			continue
 		}
 		r.Parents = append(r.Parents, membership)
 	}
+	if r.Password.Policy == "" {
+		r.Password = o.Password
+	}
+	if r.ValidUntil == nil {
+		r.ValidUntil = o.ValidUntil
+	}
+	if r.ID == "" {
+		r.ID = o.ID
+	}
 	if r.Config == nil {
 		r.Config = o.Config
 	} else if o.Config != nil {
 		maps.Copy(r.Config, o.Config)
 	}
+	if r.SecurityLabels == nil {
+		r.SecurityLabels = o.SecurityLabels
+	} else if o.SecurityLabels != nil {
+		maps.Copy(r.SecurityLabels, o.SecurityLabels)
+	}
+	if r.DatabaseConfig == nil {
+		r.DatabaseConfig = o.DatabaseConfig
+	} else {
+		for database, config := range o.DatabaseConfig {
+			if r.DatabaseConfig[database] == nil {
+				r.DatabaseConfig[database] = config
+			} else {
+				maps.Copy(r.DatabaseConfig[database], config)
+			}
+		}
+	}
+}
+
+// databaseConfig returns config of role in each managed database.
+//
+// Expands __all__ to each managed database. Config of a specific database
+// overrides __all__.
+func (r Role) databaseConfig() map[string]Config {
+	out := make(map[string]Config)
+	for database := range postgres.Databases {
+		config := Config{}
+		maps.Copy(config, r.DatabaseConfig["__all__"])
+		maps.Copy(config, r.DatabaseConfig[database])
+		if len(config) > 0 {
+			out[database] = config
+		}
+	}
+	return out
 }
@@ -2,8 +2,11 @@ package role_test
 
 import (
 	"testing"
+	"time"
 
+	"github.com/dalibo/ldap2pg/v6/internal/postgres"
 	"github.com/dalibo/ldap2pg/v6/internal/role"
+	"github.com/jackc/pgx/v5"
 	"github.com/stretchr/testify/require"
 )
 
@@ -24,3 +27,67 @@ func TestMerge(t *testing.T) {
 	r0.Merge(r1)
 	r.Equal("tata", r0.Config["a"])
 }
+
+func TestValidUntil(t *testing.T) {
+	r := require.New(t)
+
+	expiration := time.Date(2025, 12, 31, 0, 0, 0, 0, time.UTC)
+	current := role.New()
+	current.Name = "alice"
+	current.ValidUntil = &time.Time{}
+
+	wanted := current
+	wanted.ValidUntil = nil
+	r.Empty(current.Alter(wanted))
+
+	wanted.ValidUntil = &time.Time{}
+	r.Empty(current.Alter(wanted))
+
+	wanted.ValidUntil = &expiration
+	queries := current.Alter(wanted)
+	r.Len(queries, 1)
+	r.Equal(`ALTER ROLE %s VALID UNTIL %s;`, queries[0].Query)
+	r.Equal("2025-12-31T00:00:00Z", queries[0].QueryArgs[1])
+
+	queries = wanted.Create()
+	r.Contains(queries[0].Query, "VALID UNTIL %s")
+	r.Contains(queries[0].QueryArgs, "2025-12-31T00:00:00Z")
+}
+
+func TestDatabaseConfig(t *testing.T) {
+	r := require.New(t)
+
+	postgres.Databases = postgres.DBMap{
+		"oltp":      {Name: "oltp"},
+		"warehouse": {Name: "warehouse"},
+	}
+	current := role.New()
+	current.Name = "alice"
+	current.DatabaseConfig = map[string]role.Config{
+		"oltp":      {"work_mem": "4MB", "search_path": "app"},
+		"warehouse": {"work_mem": "4MB"},
+		"unmanaged": {"work_mem": "4MB"},
+	}
+
+	wanted := current
+	wanted.DatabaseConfig = nil
+	r.Empty(current.Alter(wanted))
+
+	wanted.DatabaseConfig = map[string]role.Config{
+		"__all__":   {"work_mem": "4MB"},
+		"warehouse": {"work_mem": "1GB", "search_path": "dwh"},
+	}
+	queries := current.Alter(wanted)
+	r.Len(queries, 3)
+	r.Equal(`ALTER ROLE %s IN DATABASE %s RESET %s;`, queries[0].Query)
+	r.Equal(pgx.Identifier{"oltp"}, queries[0].QueryArgs[1])
+	r.Equal(pgx.Identifier{"search_path"}, queries[0].QueryArgs[2])
+	r.Equal("Set role config.", queries[1].Description)
+	r.Equal([]any{pgx.Identifier{"alice"}, pgx.Identifier{"warehouse"}, pgx.Identifier{"search_path"}, "dwh"}, queries[1].QueryArgs)
+	r.Equal("Update role config.", queries[2].Description)
+	r.Equal("1GB", queries[2].QueryArgs[3])
+
+	queries = wanted.Create()
+	r.Equal(`ALTER ROLE %s IN DATABASE %s SET %s TO %s;`, queries[2].Query)
+	r.Len(queries, 5)
+}
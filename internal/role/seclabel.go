@@ -0,0 +1,42 @@
+package role
+
+import (
+	"maps"
+	"slices"
+
+	"github.com/dalibo/ldap2pg/v6/internal/postgres"
+	"github.com/jackc/pgx/v5"
+)
+
+// SecurityLabels of a role, indexed by provider like anon or selinux.
+type SecurityLabels map[string]string
+
+// alter generates queries to change current labels l of role name to wanted.
+func (l SecurityLabels) alter(name string, wanted SecurityLabels) (out []postgres.SyncQuery) {
+	for _, provider := range slices.Sorted(maps.Keys(l)) {
+		if _, ok := wanted[provider]; ok {
+			continue
+		}
+		out = append(out, postgres.SyncQuery{
+			Description: "Remove role security label.",
+			LogArgs:     []any{"role", name, "provider", provider},
+			Query:       `SECURITY LABEL FOR %s ON ROLE %s IS NULL;`,
+			QueryArgs:   []any{pgx.Identifier{provider}, pgx.Identifier{name}},
+		})
+	}
+
+	for _, provider := range slices.Sorted(maps.Keys(wanted)) {
+		label := wanted[provider]
+		current, ok := l[provider]
+		if ok && current == label {
+			continue
+		}
+		out = append(out, postgres.SyncQuery{
+			Description: "Set role security label.",
+			LogArgs:     []any{"role", name, "provider", provider, "label", label},
+			Query:       `SECURITY LABEL FOR %s ON ROLE %s IS %s;`,
+			QueryArgs:   []any{pgx.Identifier{provider}, pgx.Identifier{name}, label},
+		})
+	}
+	return
+}
@@ -0,0 +1,36 @@
+package role_test
+
+import (
+	"testing"
+
+	"github.com/dalibo/ldap2pg/v6/internal/role"
+	"github.com/jackc/pgx/v5"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSecurityLabels(t *testing.T) {
+	r := require.New(t)
+
+	current := role.New()
+	current.Name = "alice"
+	current.SecurityLabels = role.SecurityLabels{"anon": "MASKED", "selinux": "user_u:user_r:user_t:s0"}
+
+	wanted := current
+	wanted.SecurityLabels = nil
+	r.Empty(current.Alter(wanted))
+
+	wanted.SecurityLabels = role.SecurityLabels{"anon": "MASKED"}
+	queries := current.Alter(wanted)
+	r.Len(queries, 1)
+	r.Equal(`SECURITY LABEL FOR %s ON ROLE %s IS NULL;`, queries[0].Query)
+	r.Equal(pgx.Identifier{"selinux"}, queries[0].QueryArgs[0])
+
+	current.SecurityLabels = role.SecurityLabels{}
+	queries = current.Alter(wanted)
+	r.Len(queries, 1)
+	r.Equal(`SECURITY LABEL FOR %s ON ROLE %s IS %s;`, queries[0].Query)
+	r.Equal("MASKED", queries[0].QueryArgs[2])
+
+	queries = wanted.Create()
+	r.Equal("Set role security label.", queries[len(queries)-1].Description)
+}
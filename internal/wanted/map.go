@@ -4,6 +4,7 @@ import (
 	"errors"
 	"fmt"
 	"log/slog"
+	"strings"
 
 	"github.com/dalibo/ldap2pg/v6/internal/ldap"
 	"github.com/dalibo/ldap2pg/v6/internal/lists"
@@ -44,18 +45,27 @@ func (m Rules) DropGrants() (out Rules) {
 	return
 }
 
-func (m Rules) Run(blacklist lists.Blacklist) (roles role.Map, grants map[string][]privileges.Grant, err error) {
+// Run searches directory and generates wanted roles and grants.
+//
+// Returns ldap.ErrEntryCount errors if searches return unexpected number of
+// entries. Roles generated by other rules are still valid, but the caller must
+// not drop roles.
+//
+// conflicts is the strategy to resolve conflicting definitions of the same
+// role: error, first, last or permissive.
+func (m Rules) Run(blacklist lists.Blacklist, conflicts string) (roles role.Map, grants map[string][]privileges.Grant, err error) {
 	var errList []error
 	var ldapc ldap.Client
 	if m.HasLDAPSearches() {
-		ldapc, err = ldap.Connect()
+		ldapc, err = ldap.Open()
 		if err != nil {
 			return nil, nil, err
 		}
-		defer ldapc.Conn.Close() //nolint:errcheck
+		defer ldap.Release(ldapc)
 	}
 
 	roles = make(map[string]role.Role)
+	origins := make(map[string]origin)
 	grants = make(map[string][]privileges.Grant)
 	for i, item := range m {
 		if item.Description != "" {
@@ -65,6 +75,11 @@ func (m Rules) Run(blacklist lists.Blacklist) (roles role.Map, grants map[string
 		}
 
 		for res := range item.search(ldapc) {
+			if errors.Is(res.err, ldap.ErrEntryCount) {
+				slog.Error("Unexpected search result. Skipping role drops.", "err", res.err)
+				errList = append(errList, res.err)
+				continue
+			}
 			if res.err != nil {
 				slog.Error("Search error. Keep going.", "err", res.err)
 				errList = append(errList, res.err)
@@ -84,6 +99,11 @@ func (m Rules) Run(blacklist lists.Blacklist) (roles role.Map, grants map[string
 				}
 				current, exists := roles[role.Name]
 				if exists {
+					o, err := resolveConflicts(&current, role, origins[role.Name], newOrigin(i, item, res.result), conflicts)
+					if err != nil {
+						errList = append(errList, err)
+					}
+					origins[role.Name] = o
 					current.Merge(role)
 					role = current
 					slog.Debug("Updated wanted role.",
@@ -93,6 +113,7 @@ func (m Rules) Run(blacklist lists.Blacklist) (roles role.Map, grants map[string
 					slog.Debug("Wants role.",
 						"name", role.Name, "options", role.Options,
 						"parents", role.Parents, "comment", role.Comment)
+					origins[role.Name] = newOrigin(i, item, res.result)
 				}
 				roles[role.Name] = role
 			}
@@ -126,3 +147,51 @@ func (m Rules) Run(blacklist lists.Blacklist) (roles role.Map, grants map[string
 	}
 	return
 }
+
+// origin of a wanted role, for conflict reporting.
+type origin struct {
+	rule        int
+	description string
+	dn          string
+}
+
+func newOrigin(i int, item Step, result ldap.Result) origin {
+	o := origin{rule: i, description: item.Description}
+	if result.Entry != nil {
+		o.dn = result.Entry.DN
+	}
+	return o
+}
+
+func (o origin) LogValue() slog.Value {
+	return slog.GroupValue(
+		slog.Int("rule", o.rule),
+		slog.String("description", o.description),
+		slog.String("dn", o.dn),
+	)
+}
+
+// resolveConflicts resolves conflicting definitions of a role in current.
+//
+// current comes from first origin, r from other origin. Returns origin of
+// resolved definition, reported in later conflicts.
+func resolveConflicts(current *role.Role, r role.Role, first, other origin, strategy string) (origin, error) {
+	conflicts := current.Conflicts(r)
+	if len(conflicts) == 0 {
+		return first, nil
+	}
+	args := []any{"role", r.Name, "attributes", conflicts, "first", first, "other", other}
+	switch strategy {
+	case "error":
+		slog.Error("Conflicting role definitions.", args...)
+		return first, fmt.Errorf("role %s: conflicting %s", r.Name, strings.Join(conflicts, ", "))
+	case "last":
+		current.Override(r)
+		slog.Warn("Conflicting role definitions.", append(args, "strategy", strategy)...)
+		return other, nil
+	case "permissive":
+		current.Permit(r)
+	}
+	slog.Warn("Conflicting role definitions.", append(args, "strategy", strategy)...)
+	return first, nil
+}
@@ -1,6 +1,11 @@
 package wanted
 
 import (
+	"log/slog"
+	"maps"
+	"slices"
+	"time"
+
 	"github.com/dalibo/ldap2pg/v6/internal/ldap"
 	"github.com/dalibo/ldap2pg/v6/internal/lists"
 	"github.com/dalibo/ldap2pg/v6/internal/pyfmt"
@@ -8,13 +13,21 @@ import (
 )
 
 type RoleRule struct {
-	Name         pyfmt.Format
-	Options      role.Options
-	Comment      pyfmt.Format
-	Parents      []MembershipRule
-	Config       role.Config
-	BeforeCreate pyfmt.Format `mapstructure:"before_create"`
-	AfterCreate  pyfmt.Format `mapstructure:"after_create"`
+	Name    pyfmt.Format
+	Options OptionsRule
+	Comment pyfmt.Format
+	ID      pyfmt.Format
+	Parents []MembershipRule
+	Config  map[string]pyfmt.Format
+	// DatabaseConfig is indexed by database name or __all__.
+	DatabaseConfig map[string]map[string]pyfmt.Format `mapstructure:"database_config"`
+	// SecurityLabels is indexed by provider.
+	SecurityLabels map[string]pyfmt.Format `mapstructure:"security_labels"`
+	Password       PasswordRule
+	ValidUntil     pyfmt.Format    `mapstructure:"valid_until"`
+	BeforeCreate   role.HookFormat `mapstructure:"before_create"`
+	AfterCreate    role.HookFormat `mapstructure:"after_create"`
+	AfterAlter     role.HookFormat `mapstructure:"after_alter"`
 }
 
 func (r RoleRule) IsStatic() bool {
@@ -22,10 +35,25 @@ func (r RoleRule) IsStatic() bool {
 }
 
 func (r RoleRule) Formats() []pyfmt.Format {
-	fmts := []pyfmt.Format{r.Name, r.Comment, r.BeforeCreate, r.AfterCreate}
+	fmts := []pyfmt.Format{r.Name, r.Comment, r.BeforeCreate.SQL, r.AfterCreate.SQL, r.AfterAlter.SQL, r.Password.Value, r.ValidUntil, r.ID}
 	for _, p := range r.Parents {
 		fmts = append(fmts, p.Name)
 	}
+	for _, k := range slices.Sorted(maps.Keys(r.Options.Formats)) {
+		fmts = append(fmts, r.Options.Formats[k])
+	}
+	for _, k := range slices.Sorted(maps.Keys(r.Config)) {
+		fmts = append(fmts, r.Config[k])
+	}
+	for _, provider := range slices.Sorted(maps.Keys(r.SecurityLabels)) {
+		fmts = append(fmts, r.SecurityLabels[provider])
+	}
+	for _, database := range slices.Sorted(maps.Keys(r.DatabaseConfig)) {
+		config := r.DatabaseConfig[database]
+		for _, k := range slices.Sorted(maps.Keys(config)) {
+			fmts = append(fmts, config[k])
+		}
+	}
 	return fmts
 }
 
@@ -49,26 +77,41 @@ func (r RoleRule) Generate(results *ldap.Result) <-chan role.Role {
 		if nil == results.Entry {
 			// Case static rule.
 			role := role.Role{
-				Name:         r.Name.String(),
-				Comment:      r.Comment.String(),
-				Options:      r.Options,
-				Parents:      parents,
-				Config:       r.Config,
-				BeforeCreate: r.BeforeCreate.String(),
-				AfterCreate:  r.AfterCreate.String(),
+				Name:           r.Name.String(),
+				Comment:        r.Comment.String(),
+				Options:        r.Options.Generate(results, r.Name.String()),
+				Parents:        parents,
+				Config:         generateConfig(r.Config, results),
+				DatabaseConfig: r.generateDatabaseConfig(results),
+				SecurityLabels: role.SecurityLabels(generateConfig(r.SecurityLabels, results)),
+				BeforeCreate:   r.BeforeCreate.Render(nil),
+				Password:       r.Password.Generate(results, r.Name.String()),
+				ValidUntil:     r.generateValidUntil(results, r.Name.String()),
+				ID:             role.SanitizeID(r.ID.String()),
+				AfterCreate:    r.AfterCreate.Render(nil),
+				AfterAlter:     r.AfterAlter.Render(nil),
 			}
 			ch <- role
 		} else {
 			// Case dynamic rule.
-			for values := range results.GenerateValues(r.Name, r.Comment, r.BeforeCreate, r.AfterCreate) {
+			id := role.SanitizeID(firstValue(results, r.ID))
+			config := generateConfig(r.Config, results)
+			labels := role.SecurityLabels(generateConfig(r.SecurityLabels, results))
+			for values := range results.GenerateValues(r.Name, r.Comment, r.BeforeCreate.SQL, r.AfterCreate.SQL, r.AfterAlter.SQL) {
 				role := role.Role{}
 				role.Name = r.Name.Format(values)
 				role.Comment = r.Comment.Format(values)
-				role.Options = r.Options
+				role.Options = r.Options.Generate(results, role.Name)
 				role.Parents = append(parents[0:0], parents...) // copy
-				role.Config = r.Config
-				role.BeforeCreate = r.BeforeCreate.Format(values)
-				role.AfterCreate = r.AfterCreate.Format(values)
+				role.Config = maps.Clone(config)
+				role.DatabaseConfig = r.generateDatabaseConfig(results)
+				role.SecurityLabels = maps.Clone(labels)
+				role.Password = r.Password.Generate(results, role.Name)
+				role.ValidUntil = r.generateValidUntil(results, role.Name)
+				role.ID = id
+				role.BeforeCreate = r.BeforeCreate.Render(values)
+				role.AfterCreate = r.AfterCreate.Render(values)
+				role.AfterAlter = r.AfterAlter.Render(values)
 				ch <- role
 			}
 		}
@@ -76,8 +119,128 @@ func (r RoleRule) Generate(results *ldap.Result) <-chan role.Role {
 	return ch
 }
 
+// generateConfig formats role config or security labels from first values of
+// LDAP attributes.
+//
+// Ignores parameter with a missing attribute. Returns nil if config is not
+// managed.
+func generateConfig(formats map[string]pyfmt.Format, results *ldap.Result) role.Config {
+	if formats == nil {
+		return nil
+	}
+	config := make(role.Config)
+	for k, f := range formats {
+		value := firstValue(results, f)
+		if value == "" && !f.IsStatic() {
+			continue
+		}
+		config[k] = value
+	}
+	return config
+}
+
+// generateDatabaseConfig formats role config in each database.
+//
+// Returns nil if database config is not managed.
+func (r RoleRule) generateDatabaseConfig(results *ldap.Result) map[string]role.Config {
+	if r.DatabaseConfig == nil {
+		return nil
+	}
+	out := make(map[string]role.Config)
+	for database, formats := range r.DatabaseConfig {
+		out[database] = generateConfig(formats, results)
+	}
+	return out
+}
+
+// generateValidUntil parses role expiration from LDAP time.
+//
+// Uses first value of LDAP attributes. A missing attribute means infinity.
+// Returns nil if unmanaged or invalid.
+func (r RoleRule) generateValidUntil(results *ldap.Result, name string) *time.Time {
+	if r.ValidUntil.Input == "" {
+		return nil
+	}
+	value := firstValue(results, r.ValidUntil)
+	t, err := ldap.ParseTime(value)
+	if err != nil {
+		slog.Warn("Ignoring invalid role expiration.", "role", name, "err", err)
+		return nil
+	}
+	return &t
+}
+
+// firstValue formats f with first values of LDAP attributes.
+//
+// Returns an empty string if an attribute is missing.
+func firstValue(results *ldap.Result, f pyfmt.Format) (value string) {
+	if results.Entry == nil || f.IsStatic() {
+		return f.String()
+	}
+	// Consume all values to release generator.
+	for values := range results.GenerateValues(f) {
+		if value == "" {
+			value = f.Format(values)
+		}
+	}
+	return
+}
+
+// OptionsRule generates role options.
+//
+// Formats overrides static options from first values of LDAP attributes.
+type OptionsRule struct {
+	role.Options
+	Formats map[string]pyfmt.Format
+}
+
+// Generate options of role.
+//
+// Keeps static option on missing attribute or invalid value.
+func (o OptionsRule) Generate(results *ldap.Result, name string) role.Options {
+	options := o.Options
+	for _, token := range slices.Sorted(maps.Keys(o.Formats)) {
+		value := firstValue(results, o.Formats[token])
+		if value == "" {
+			continue
+		}
+		err := options.Set(token, value)
+		if err != nil {
+			slog.Warn("Ignoring invalid role option.", "role", name, "err", err)
+		}
+	}
+	return options
+}
+
+// PasswordRule generates role password from a SCRAM-SHA-256 verifier.
+type PasswordRule struct {
+	Value  pyfmt.Format
+	Policy string
+}
+
+// Generate password of role name.
+//
+// A missing attribute means no password.
+// Ignores value not hashed with SCRAM-SHA-256 to never send a clear text
+// password.
+func (p PasswordRule) Generate(results *ldap.Result, name string) (password role.Password) {
+	password.Policy = p.Policy
+	if p.Policy == "" || p.Policy == "null" {
+		return
+	}
+	password.Hash = firstValue(results, p.Value)
+	if password.Hash != "" && !role.IsVerifier(password.Hash) {
+		slog.Warn("Ignoring password not hashed with SCRAM-SHA-256.", "role", name)
+		password.Hash = ""
+	}
+	return
+}
+
 type MembershipRule struct {
-	Name pyfmt.Format
+	Name    pyfmt.Format
+	Admin   *bool
+	Inherit *bool
+	Set     *bool
 }
 
 func (m MembershipRule) String() string {
@@ -90,6 +253,9 @@ func (m MembershipRule) IsStatic() bool {
 
 func (m MembershipRule) Generate(values map[string]string) role.Membership {
 	return role.Membership{
-		Name: m.Name.Format(values),
+		Name:    m.Name.Format(values),
+		Admin:   m.Admin,
+		Inherit: m.Inherit,
+		Set:     m.Set,
 	}
 }
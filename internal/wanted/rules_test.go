@@ -0,0 +1,260 @@
+package wanted_test
+
+import (
+	"bytes"
+	"log/slog"
+	"strings"
+
+	"github.com/dalibo/ldap2pg/v6/internal/ldap"
+	"github.com/dalibo/ldap2pg/v6/internal/pyfmt"
+	"github.com/dalibo/ldap2pg/v6/internal/role"
+	"github.com/dalibo/ldap2pg/v6/internal/wanted"
+	ldap3 "github.com/go-ldap/ldap/v3"
+)
+
+func (suite *Suite) TestPasswordGenerate() {
+	r := suite.Require()
+
+	value, err := pyfmt.Parse("{userPassword}")
+	r.Nil(err)
+	rule := wanted.PasswordRule{Value: value, Policy: "always"}
+	verifier := "SCRAM-SHA-256$4096:c2FsdA==$c3RvcmVk:c2VydmVy"
+
+	results := &ldap.Result{Entry: ldap3.NewEntry("cn=alice", map[string][]string{
+		"userPassword": {verifier},
+	})}
+	password := rule.Generate(results, "alice")
+	r.Equal("always", password.Policy)
+	r.Equal(verifier, password.Hash)
+
+	// Never send clear text password.
+	results.Entry = ldap3.NewEntry("cn=bob", map[string][]string{
+		"userPassword": {"secret"},
+	})
+	password = rule.Generate(results, "bob")
+	r.Equal("", password.Hash)
+
+	// Missing attribute means no password.
+	results.Entry = ldap3.NewEntry("cn=carol", map[string][]string{})
+	password = rule.Generate(results, "carol")
+	r.Equal("", password.Hash)
+}
+
+func (suite *Suite) TestValidUntilGenerate() {
+	r := suite.Require()
+
+	c := configFromYAML(`
+	rules:
+	- ldapsearch:
+	    base: cn=toto
+	  roles:
+	  - name: "{cn}"
+	    valid_until: "{accountExpires}"
+	`)
+	rule := c.Rules[0].RoleRules[0]
+
+	results := &ldap.Result{Entry: ldap3.NewEntry("cn=alice", map[string][]string{
+		"cn":             {"alice"},
+		"accountExpires": {"134116128000000000"},
+	})}
+	var roles []role.Role
+	for role := range rule.Generate(results) {
+		roles = append(roles, role)
+	}
+	r.Len(roles, 1)
+	r.NotNil(roles[0].ValidUntil)
+	r.Equal("2025-12-31T00:00:00Z", role.ValidUntilString(roles[0].ValidUntil))
+
+	// Missing attribute means never expires.
+	results.Entry = ldap3.NewEntry("cn=bob", map[string][]string{"cn": {"bob"}})
+	for role := range rule.Generate(results) {
+		r.NotNil(role.ValidUntil)
+		r.True(role.ValidUntil.IsZero())
+	}
+}
+
+func (suite *Suite) TestConfigGenerate() {
+	r := suite.Require()
+
+	c := configFromYAML(`
+	rules:
+	- ldapsearch:
+	    base: cn=toto
+	  roles:
+	  - name: "{cn}"
+	    config:
+	      search_path: '"$user", {department.lower()}'
+	      statement_timeout: 3000
+	`)
+	item := c.Rules[0]
+	item.InferAttributes()
+	r.ElementsMatch([]string{"cn", "department"}, item.LdapSearch.Attributes)
+	rule := item.RoleRules[0]
+
+	results := &ldap.Result{Entry: ldap3.NewEntry("cn=alice", map[string][]string{
+		"cn":         {"alice"},
+		"department": {"Sales"},
+	})}
+	for role := range rule.Generate(results) {
+		r.Equal(`"$user", sales`, role.Config["search_path"])
+		r.Equal("3000", role.Config["statement_timeout"])
+	}
+
+	// Missing attribute ignores parameter.
+	results.Entry = ldap3.NewEntry("cn=bob", map[string][]string{"cn": {"bob"}})
+	for role := range rule.Generate(results) {
+		r.NotContains(role.Config, "search_path")
+		r.Equal("3000", role.Config["statement_timeout"])
+	}
+}
+
+func (suite *Suite) TestOptionsGenerate() {
+	r := suite.Require()
+
+	c := configFromYAML(`
+	rules:
+	- ldapsearch:
+	    base: cn=toto
+	  roles:
+	  - name: "{cn}"
+	    options:
+	      LOGIN: "{userAccountControl.noflag(2)}"
+	      CONNECTION LIMIT: "{maxConnections}"
+	      INHERIT: true
+	`)
+	r.Len(c.Rules, 1)
+	item := c.Rules[0]
+	item.InferAttributes()
+	r.ElementsMatch([]string{"cn", "maxConnections", "userAccountControl"}, item.LdapSearch.Attributes)
+	rule := item.RoleRules[0]
+	r.Equal([]string{"CONNECTION LIMIT", "INHERIT", "LOGIN"}, rule.Options.Managed)
+
+	results := &ldap.Result{Entry: ldap3.NewEntry("cn=alice", map[string][]string{
+		"cn":                 {"alice"},
+		"userAccountControl": {"512"},
+		"maxConnections":     {"5"},
+	})}
+	for role := range rule.Generate(results) {
+		r.True(role.Options.CanLogin)
+		r.True(role.Options.Inherit)
+		r.Equal(5, role.Options.ConnLimit)
+	}
+
+	// Disabled account. Missing attribute keeps default.
+	results.Entry = ldap3.NewEntry("cn=bob", map[string][]string{
+		"cn":                 {"bob"},
+		"userAccountControl": {"514"},
+	})
+	for role := range rule.Generate(results) {
+		r.False(role.Options.CanLogin)
+		r.Equal(-1, role.Options.ConnLimit)
+	}
+}
+
+func (suite *Suite) TestRunConflicts() {
+	r := suite.Require()
+
+	c := configFromYAML(`
+	rules:
+	- description: Developers
+	  roles:
+	  - name: alice
+	    comment: Developer
+	    options:
+	      LOGIN: true
+	- description: Admins
+	  roles:
+	  - name: alice
+	    comment: Admin
+	    options:
+	      LOGIN: false
+	      CREATEDB: true
+	`)
+
+	roles, _, err := c.Rules.Run(nil, "first")
+	r.Nil(err)
+	r.Equal("Developer", roles["alice"].Comment)
+	r.True(roles["alice"].Options.CanLogin)
+	r.True(roles["alice"].Options.CreateDB)
+
+	roles, _, err = c.Rules.Run(nil, "last")
+	r.Nil(err)
+	r.Equal("Admin", roles["alice"].Comment)
+	r.False(roles["alice"].Options.CanLogin)
+
+	roles, _, err = c.Rules.Run(nil, "permissive")
+	r.Nil(err)
+	r.Equal("Developer", roles["alice"].Comment)
+	r.True(roles["alice"].Options.CanLogin)
+
+	_, _, err = c.Rules.Run(nil, "error")
+	r.ErrorContains(err, "role alice: conflicting comment, LOGIN")
+}
+
+func (suite *Suite) TestRunConflictsLast() {
+	r := suite.Require()
+
+	c := configFromYAML(`
+	rules:
+	- description: Developers
+	  roles:
+	  - name: alice
+	    comment: Developer
+	- description: Admins
+	  roles:
+	  - name: alice
+	    comment: Admin
+	- description: Auditors
+	  roles:
+	  - name: alice
+	    comment: Auditor
+	- description: Readers
+	  roles:
+	  - name: alice
+	    comment: Auditor
+	`)
+
+	var buf bytes.Buffer
+	defer slog.SetDefault(slog.Default())
+	slog.SetDefault(slog.New(slog.NewTextHandler(&buf, nil)))
+
+	roles, _, err := c.Rules.Run(nil, "last")
+	r.Nil(err)
+	r.Equal("Auditor", roles["alice"].Comment)
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	var conflicts []string
+	for _, line := range lines {
+		if strings.Contains(line, "Conflicting role definitions.") {
+			conflicts = append(conflicts, line)
+		}
+	}
+	// Readers agrees with Auditors.
+	r.Len(conflicts, 2)
+	r.Contains(conflicts[0], "first.description=Developers")
+	r.Contains(conflicts[0], "other.description=Admins")
+	// Admins overrides Developers definition.
+	r.Contains(conflicts[1], "first.description=Admins")
+	r.Contains(conflicts[1], "other.description=Auditors")
+}
+
+func (suite *Suite) TestRunNoConflictWithoutOptions() {
+	r := suite.Require()
+
+	c := loadConfig(`
+	rules:
+	- roles:
+	  - name: alice
+	    options: LOGIN
+	- roles:
+	  - name: alice
+	- roles:
+	  - name: alice
+	    parents: [readers]
+	  - readers
+	`)
+
+	roles, _, err := c.Rules.Run(nil, "error")
+	r.Nil(err)
+	r.True(roles["alice"].Options.CanLogin)
+}
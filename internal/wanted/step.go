@@ -1,6 +1,7 @@
 package wanted
 
 import (
+	"fmt"
 	"log/slog"
 	"slices"
 	"strings"
@@ -190,6 +191,11 @@ func (s Step) search(ldapc ldap.Client) <-chan SearchResult {
 			ch <- SearchResult{err: err}
 			return
 		}
+		err = search.CheckCount(len(res.Entries))
+		if err != nil {
+			ch <- SearchResult{err: fmt.Errorf("%s: %w", search.Base, err)}
+			return
+		}
 		subsearchAttr := s.LdapSearch.SubsearchAttribute()
 		for _, entry := range res.Entries {
 			slog.Debug("Got LDAP entry.", "dn", entry.DN)
@@ -17,6 +17,16 @@ func configFromYAML(rawYAML string) (c config.Config) {
 	return
 }
 
+// loadConfig normalizes YAML like configuration file.
+func loadConfig(rawYAML string) (c config.Config) {
+	rawYAML = dedent.Dedent(rawYAML)
+	var out any
+	_ = yaml.Unmarshal([]byte(rawYAML), &out)
+	root, _ := config.NormalizeConfigRoot(out)
+	_ = c.LoadYaml(root)
+	return
+}
+
 func (suite *Suite) TestItemStatic() {
 	r := suite.Require()
 